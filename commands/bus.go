@@ -0,0 +1,127 @@
+// Package commands provides a command bus for invoking application use cases
+// in a uniform way.
+//
+// Contrary to domain events, which may be observed by any number of listeners,
+// each command type is handled by exactly one handler. Cross-cutting concerns
+// such as validation, logging, authorization or transactions are applied to
+// every command by chaining middlewares around handlers.
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/tangelo-labs/go-domain/internal/bus"
+)
+
+// Bus-related errors.
+var (
+	// ErrHandlerNotFound is returned when dispatching a command whose type has
+	// no registered handler.
+	ErrHandlerNotFound = errors.New("command handler not found")
+
+	// ErrHandlerAlreadyRegistered is returned when registering a handler for a
+	// command type that already has one.
+	ErrHandlerAlreadyRegistered = errors.New("command handler already registered")
+
+	// ErrInvalidCommandType is returned when registering a handler for a
+	// command type that cannot be routed, such as interfaces.
+	ErrInvalidCommandType = errors.New("invalid command type")
+
+	// ErrUnexpectedResult is returned when a handler result cannot be converted
+	// into the type expected by the caller.
+	ErrUnexpectedResult = errors.New("unexpected command result")
+)
+
+// Command represents an intention to change the state of the system.
+//
+// Commands are always named with an imperative verb, such as "ConfirmOrder".
+// Contrary to events, they may be rejected.
+type Command interface{}
+
+// Void is the result type of commands that produce no result.
+type Void struct{}
+
+// HandlerFunc is the untyped form of a command handler, as seen by
+// middlewares.
+type HandlerFunc func(ctx context.Context, cmd Command) (interface{}, error)
+
+// Middleware wraps a command handler to add behavior before and/or after its
+// invocation.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Bus routes commands to their handlers.
+type Bus interface {
+	// Dispatch sends the given command to its handler and returns its result.
+	// Commands are routed by their dynamic type, so dispatching a pointer to a
+	// command is not the same as dispatching the command itself.
+	Dispatch(ctx context.Context, cmd Command) (interface{}, error)
+
+	// Use appends the given middlewares to the chain applied to every handler.
+	// Middlewares are invoked in the order they were added, the first one being
+	// the outermost.
+	Use(mws ...Middleware)
+
+	// RegisterHandler registers the untyped handler for commands of the given
+	// type. Most callers should prefer the typed Register function, which
+	// relies on this method.
+	RegisterHandler(cmdType reflect.Type, handler HandlerFunc) error
+}
+
+// NewBus builds a new command bus with the given middlewares. This object can
+// be safely shared by multiple goroutines.
+func NewBus(mws ...Middleware) Bus {
+	return bus.New[Command, HandlerFunc]("command", bus.Errors{
+		NotFound:          ErrHandlerNotFound,
+		AlreadyRegistered: ErrHandlerAlreadyRegistered,
+		InvalidType:       ErrInvalidCommandType,
+	}, mws...)
+}
+
+// Register registers the handler for commands of type C, producing results of
+// type R.
+func Register[C, R any](b Bus, handler func(ctx context.Context, cmd C) (R, error)) error {
+	cmdType := reflect.TypeOf((*C)(nil)).Elem()
+
+	return b.RegisterHandler(cmdType, func(ctx context.Context, cmd Command) (interface{}, error) {
+		typed, ok := cmd.(C)
+		if !ok {
+			return nil, fmt.Errorf("%w: handler for %s received %T", ErrInvalidCommandType, cmdType, cmd)
+		}
+
+		return handler(ctx, typed)
+	})
+}
+
+// RegisterVoid registers the handler for commands of type C that produce no
+// result.
+func RegisterVoid[C any](b Bus, handler func(ctx context.Context, cmd C) error) error {
+	return Register(b, func(ctx context.Context, cmd C) (Void, error) {
+		return Void{}, handler(ctx, cmd)
+	})
+}
+
+// Send dispatches the given command through the given bus and converts the
+// handler result into the type R.
+func Send[R, C any](ctx context.Context, b Bus, cmd C) (R, error) {
+	var zero R
+
+	result, err := b.Dispatch(ctx, cmd)
+	if err != nil {
+		return zero, err
+	}
+
+	if result == nil {
+		return zero, nil
+	}
+
+	typed, ok := result.(R)
+	if !ok {
+		return zero, fmt.Errorf("%w: expected %s for command %T, got %T",
+			ErrUnexpectedResult, reflect.TypeOf((*R)(nil)).Elem(), cmd, result)
+	}
+
+	return typed, nil
+}
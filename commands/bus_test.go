@@ -0,0 +1,232 @@
+package commands_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain"
+	"github.com/tangelo-labs/go-domain/commands"
+)
+
+func TestBus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("GIVEN a bus with one handler for createOrder commands", func(t *testing.T) {
+		bus := commands.NewBus()
+
+		require.NoError(t, commands.Register(bus, func(ctx context.Context, cmd createOrder) (domain.ID, error) {
+			return cmd.ID, nil
+		}))
+
+		t.Run("WHEN sending a createOrder command THEN typed result is returned", func(t *testing.T) {
+			id := domain.NewID()
+
+			result, err := commands.Send[domain.ID](ctx, bus, createOrder{ID: id})
+			require.NoError(t, err)
+			require.Equal(t, id, result)
+		})
+
+		t.Run("WHEN registering a second handler for createOrder commands THEN registration fails", func(t *testing.T) {
+			err := commands.RegisterVoid(bus, func(ctx context.Context, cmd createOrder) error {
+				return nil
+			})
+
+			require.ErrorIs(t, err, commands.ErrHandlerAlreadyRegistered)
+		})
+
+		t.Run("WHEN dispatching a command with no handler THEN dispatch fails", func(t *testing.T) {
+			_, err := bus.Dispatch(ctx, cancelOrder{ID: domain.NewID()})
+			require.ErrorIs(t, err, commands.ErrHandlerNotFound)
+		})
+
+		t.Run("WHEN dispatching a createOrder command by pointer THEN dispatch fails", func(t *testing.T) {
+			_, err := bus.Dispatch(ctx, &createOrder{ID: domain.NewID()})
+			require.ErrorIs(t, err, commands.ErrHandlerNotFound)
+		})
+
+		t.Run("WHEN sending expecting the wrong result type THEN an unexpected result error is returned", func(t *testing.T) {
+			_, err := commands.Send[int](ctx, bus, createOrder{ID: domain.NewID()})
+			require.ErrorIs(t, err, commands.ErrUnexpectedResult)
+		})
+	})
+
+	t.Run("GIVEN a bus WHEN registering a handler for an interface command type THEN registration fails", func(t *testing.T) {
+		bus := commands.NewBus()
+
+		err := commands.RegisterVoid(bus, func(ctx context.Context, cmd fmt.Stringer) error {
+			return nil
+		})
+
+		require.ErrorIs(t, err, commands.ErrInvalidCommandType)
+	})
+
+	t.Run("GIVEN a bus implemented outside the commands package", func(t *testing.T) {
+		bus := &registryBus{Bus: commands.NewBus()}
+
+		t.Run("WHEN registering a handler THEN it goes through the custom bus AND commands are handled", func(t *testing.T) {
+			require.NoError(t, commands.RegisterVoid(bus, func(ctx context.Context, cmd cancelOrder) error {
+				return nil
+			}))
+
+			_, err := commands.Send[commands.Void](ctx, bus, cancelOrder{ID: domain.NewID()})
+			require.NoError(t, err)
+			require.Equal(t, []string{"commands_test.cancelOrder"}, bus.registered)
+		})
+	})
+
+	t.Run("GIVEN a bus with two tracing middlewares", func(t *testing.T) {
+		trace := make([]string, 0)
+		tracer := func(name string) commands.Middleware {
+			return func(next commands.HandlerFunc) commands.HandlerFunc {
+				return func(ctx context.Context, cmd commands.Command) (interface{}, error) {
+					trace = append(trace, name+":before")
+					result, err := next(ctx, cmd)
+					trace = append(trace, name+":after")
+
+					return result, err
+				}
+			}
+		}
+
+		bus := commands.NewBus(tracer("a"))
+		bus.Use(tracer("b"))
+
+		require.NoError(t, commands.RegisterVoid(bus, func(ctx context.Context, cmd cancelOrder) error {
+			trace = append(trace, "handler")
+
+			return nil
+		}))
+
+		t.Run("WHEN dispatching a command THEN middlewares are invoked in registration order", func(t *testing.T) {
+			_, err := commands.Send[commands.Void](ctx, bus, cancelOrder{ID: domain.NewID()})
+			require.NoError(t, err)
+			require.Equal(t, []string{"a:before", "b:before", "handler", "b:after", "a:after"}, trace)
+		})
+	})
+
+	t.Run("GIVEN a bus with validation and authorization middlewares", func(t *testing.T) {
+		errForbidden := errors.New("forbidden")
+		calls := 0
+		bus := commands.NewBus(
+			commands.Validation(),
+			commands.Authorization(func(ctx context.Context, cmd commands.Command) error {
+				if c, ok := cmd.(createOrder); ok && c.Customer == "mallory" {
+					return errForbidden
+				}
+
+				return nil
+			}),
+		)
+
+		require.NoError(t, commands.Register(bus, func(ctx context.Context, cmd createOrder) (domain.ID, error) {
+			calls++
+
+			return cmd.ID, nil
+		}))
+
+		t.Run("WHEN dispatching an invalid command THEN handler is not invoked AND validation error is returned", func(t *testing.T) {
+			_, err := bus.Dispatch(ctx, createOrder{Customer: gofakeit.Name()})
+			require.ErrorIs(t, err, commands.ErrInvalidCommand)
+			require.ErrorIs(t, err, errMissingID)
+			require.Zero(t, calls)
+		})
+
+		t.Run("WHEN dispatching an unauthorized command THEN handler is not invoked AND authorization error is returned", func(t *testing.T) {
+			_, err := bus.Dispatch(ctx, createOrder{ID: domain.NewID(), Customer: "mallory"})
+			require.ErrorIs(t, err, commands.ErrUnauthorized)
+			require.ErrorIs(t, err, errForbidden)
+			require.Zero(t, calls)
+		})
+
+		t.Run("WHEN dispatching a valid and authorized command THEN handler is invoked", func(t *testing.T) {
+			_, err := bus.Dispatch(ctx, createOrder{ID: domain.NewID(), Customer: gofakeit.Name()})
+			require.NoError(t, err)
+			require.Equal(t, 1, calls)
+		})
+	})
+
+	t.Run("GIVEN a bus with a transactional middleware", func(t *testing.T) {
+		uow := &fakeUnitOfWork{}
+		bus := commands.NewBus(commands.Transactional(func(ctx context.Context) (context.Context, commands.UnitOfWork, error) {
+			return ctx, uow, nil
+		}))
+
+		errHandler := errors.New("handler failed")
+
+		require.NoError(t, commands.RegisterVoid(bus, func(ctx context.Context, cmd cancelOrder) error {
+			if cmd.ID.IsEmpty() {
+				return errHandler
+			}
+
+			return nil
+		}))
+
+		t.Run("WHEN handler succeeds THEN unit of work is committed", func(t *testing.T) {
+			_, err := bus.Dispatch(ctx, cancelOrder{ID: domain.NewID()})
+			require.NoError(t, err)
+			require.Equal(t, 1, uow.commits)
+			require.Zero(t, uow.rollbacks)
+		})
+
+		t.Run("WHEN handler fails THEN unit of work is rolled back", func(t *testing.T) {
+			_, err := bus.Dispatch(ctx, cancelOrder{})
+			require.ErrorIs(t, err, errHandler)
+			require.Equal(t, 1, uow.commits)
+			require.Equal(t, 1, uow.rollbacks)
+		})
+	})
+}
+
+var errMissingID = errors.New("missing order id")
+
+type createOrder struct {
+	ID       domain.ID
+	Customer string
+}
+
+func (c createOrder) Validate() error {
+	if c.ID.IsEmpty() {
+		return errMissingID
+	}
+
+	return nil
+}
+
+type cancelOrder struct {
+	ID domain.ID
+}
+
+type registryBus struct {
+	commands.Bus
+	registered []string
+}
+
+func (r *registryBus) RegisterHandler(cmdType reflect.Type, handler commands.HandlerFunc) error {
+	r.registered = append(r.registered, cmdType.String())
+
+	return r.Bus.RegisterHandler(cmdType, handler)
+}
+
+type fakeUnitOfWork struct {
+	commits   int
+	rollbacks int
+}
+
+func (f *fakeUnitOfWork) Commit(context.Context) error {
+	f.commits++
+
+	return nil
+}
+
+func (f *fakeUnitOfWork) Rollback(context.Context) error {
+	f.rollbacks++
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Middleware-related errors.
+var (
+	// ErrInvalidCommand is returned by the Validation middleware when a
+	// command fails to validate.
+	ErrInvalidCommand = errors.New("invalid command")
+
+	// ErrUnauthorized is returned by the Authorization middleware when a
+	// command is rejected by the authorizer.
+	ErrUnauthorized = errors.New("unauthorized command")
+)
+
+// Validator defines a command capable of validating itself.
+type Validator interface {
+	// Validate returns a non-nil error if the command is not valid.
+	Validate() error
+}
+
+// Validation builds a middleware that rejects commands implementing the
+// Validator interface whose validation fails. The handler is not invoked for
+// such commands.
+func Validation() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (interface{}, error) {
+			if v, ok := cmd.(Validator); ok {
+				if err := v.Validate(); err != nil {
+					return nil, fmt.Errorf("%w: %T: %w", ErrInvalidCommand, cmd, err)
+				}
+			}
+
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// AuthorizerFunc decides whether the given command is allowed to be executed
+// in the given context, usually by inspecting the identity carried by it.
+// A non-nil error rejects the command.
+type AuthorizerFunc func(ctx context.Context, cmd Command) error
+
+// Authorization builds a middleware that rejects commands refused by the
+// given authorizer. The handler is not invoked for such commands.
+func Authorization(authorize AuthorizerFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (interface{}, error) {
+			if err := authorize(ctx, cmd); err != nil {
+				return nil, fmt.Errorf("%w: %T: %w", ErrUnauthorized, cmd, err)
+			}
+
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// Logging builds a middleware that logs the outcome and duration of every
+// command using the given logger.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (interface{}, error) {
+			start := time.Now()
+			result, err := next(ctx, cmd)
+			attrs := []slog.Attr{
+				slog.String("command", fmt.Sprintf("%T", cmd)),
+				slog.Duration("duration", time.Since(start)),
+			}
+
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "command failed", append(attrs, slog.Any("error", err))...)
+
+				return result, err
+			}
+
+			logger.LogAttrs(ctx, slog.LevelDebug, "command handled", attrs...)
+
+			return result, nil
+		}
+	}
+}
+
+// UnitOfWork represents a transactional boundary, such as a database
+// transaction, spanning the execution of a command handler.
+type UnitOfWork interface {
+	// Commit makes permanent every change done within the unit of work.
+	Commit(ctx context.Context) error
+
+	// Rollback discards every change done within the unit of work.
+	Rollback(ctx context.Context) error
+}
+
+// BeginFunc starts a new unit of work. The returned context is passed down to
+// the handler, so it can carry the unit of work itself.
+type BeginFunc func(ctx context.Context) (context.Context, UnitOfWork, error)
+
+// Transactional builds a middleware that runs each handler within a unit of
+// work started by the given function. The unit of work is committed if the
+// handler succeeds, and rolled back if it fails or panics.
+func Transactional(begin BeginFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, cmd Command) (result interface{}, err error) {
+			uowCtx, uow, err := begin(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("%w: could not begin unit of work for command %T", err, cmd)
+			}
+
+			defer func() {
+				if r := recover(); r != nil {
+					_ = uow.Rollback(ctx)
+
+					panic(r)
+				}
+			}()
+
+			result, err = next(uowCtx, cmd)
+			if err != nil {
+				if rErr := uow.Rollback(ctx); rErr != nil {
+					return nil, errors.Join(err, fmt.Errorf("%w: could not rollback unit of work for command %T", rErr, cmd))
+				}
+
+				return nil, err
+			}
+
+			if cErr := uow.Commit(ctx); cErr != nil {
+				return nil, fmt.Errorf("%w: could not commit unit of work for command %T", cErr, cmd)
+			}
+
+			return result, nil
+		}
+	}
+}
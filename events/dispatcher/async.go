@@ -0,0 +1,350 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/observe"
+)
+
+// Async dispatcher related errors.
+var (
+	// ErrDispatcherClosed is returned when dispatching events through an
+	// asynchronous dispatcher that was shut down.
+	ErrDispatcherClosed = errors.New("dispatcher closed")
+
+	// ErrQueueFull is returned, or reported, when an event cannot be enqueued
+	// because the queue of its partition is full.
+	ErrQueueFull = errors.New("dispatch queue full")
+)
+
+// BackpressurePolicy defines how an asynchronous dispatcher behaves when the
+// queue an event must be enqueued into is full.
+type BackpressurePolicy int
+
+const (
+	// Block makes Dispatch wait until there is room in the queue, the
+	// dispatcher is shut down, or the given context is done. This is the
+	// default policy.
+	Block BackpressurePolicy = iota
+
+	// Reject makes Dispatch fail with ErrQueueFull.
+	Reject
+
+	// DropNewest discards the event being dispatched, and reports
+	// ErrQueueFull to the error callback, if any. Dispatch does not fail.
+	DropNewest
+)
+
+// AsyncOption configures an asynchronous dispatcher.
+type AsyncOption func(*asyncOptions)
+
+type asyncOptions struct {
+	workers      int
+	queueSize    int
+	partitionKey func(events.Event) string
+	backpressure BackpressurePolicy
+	onError      func(error)
+	observer     observe.Observer
+}
+
+// WithWorkers sets the number of workers, and hence of partitions, events are
+// distributed across. Defaults to 1.
+func WithWorkers(n int) AsyncOption {
+	return func(o *asyncOptions) {
+		o.workers = n
+	}
+}
+
+// WithQueueSize sets the capacity of the queue of each worker. Defaults to 64.
+func WithQueueSize(n int) AsyncOption {
+	return func(o *asyncOptions) {
+		o.queueSize = n
+	}
+}
+
+// WithPartitionKey sets the function that computes the partition key of each
+// event. Events sharing a partition key are delivered in the same order they
+// were dispatched, while events with an empty key are distributed round-robin
+// with no ordering guarantees. Defaults to EnvelopeAggregateID.
+func WithPartitionKey(fn func(events.Event) string) AsyncOption {
+	return func(o *asyncOptions) {
+		o.partitionKey = fn
+	}
+}
+
+// WithBackpressure sets the behavior of the dispatcher when a queue is full,
+// see BackpressurePolicy.
+func WithBackpressure(policy BackpressurePolicy) AsyncOption {
+	return func(o *asyncOptions) {
+		o.backpressure = policy
+	}
+}
+
+// WithAsyncErrorCallback sets a function that is called with every failure
+// that cannot be returned to the caller of Dispatch, such as failures of the
+// underlying dispatcher or events dropped due to backpressure.
+func WithAsyncErrorCallback(fn func(err error)) AsyncOption {
+	return func(o *asyncOptions) {
+		o.onError = fn
+	}
+}
+
+// EnvelopeAggregateID is a partition key function that partitions events by
+// the aggregate ID of their envelope. Events not being envelopes have no key.
+func EnvelopeAggregateID(event events.Event) string {
+	if env, ok := event.(events.Envelope); ok {
+		return env.AggregateID
+	}
+
+	return ""
+}
+
+type asyncItem struct {
+	ctx   context.Context
+	topic string
+	event events.Event
+}
+
+// AsyncDispatcher is a Dispatcher that hands events over to a pool of workers,
+// which in turn dispatch them through an underlying dispatcher. Dispatch
+// returns as soon as the event is enqueued, so slow handlers do not slow
+// down the caller.
+type AsyncDispatcher struct {
+	next     Dispatcher
+	opts     asyncOptions
+	queues   []chan asyncItem
+	rr       uint64
+	closing  chan struct{}
+	closed   bool
+	mu       sync.RWMutex
+	inflight sync.WaitGroup
+	workers  sync.WaitGroup
+}
+
+// NewAsyncDispatcher builds an asynchronous dispatcher on top of the given
+// one, which is used to register subscribers and deliver events to them.
+// Workers are started right away, and must be stopped by calling Shutdown.
+func NewAsyncDispatcher(next Dispatcher, opts ...AsyncOption) *AsyncDispatcher {
+	o := asyncOptions{
+		workers:      1,
+		queueSize:    64,
+		partitionKey: EnvelopeAggregateID,
+		observer:     observe.Nop{},
+	}
+
+	for i := range opts {
+		opts[i](&o)
+	}
+
+	if o.workers < 1 {
+		o.workers = 1
+	}
+
+	if o.queueSize < 0 {
+		o.queueSize = 0
+	}
+
+	a := &AsyncDispatcher{
+		next:    next,
+		opts:    o,
+		queues:  make([]chan asyncItem, o.workers),
+		closing: make(chan struct{}),
+	}
+
+	for i := range a.queues {
+		a.queues[i] = make(chan asyncItem, o.queueSize)
+		a.workers.Add(1)
+
+		go a.work(a.queues[i])
+	}
+
+	return a
+}
+
+// Dispatch enqueues the given event to be dispatched by a worker to the
+// default topic, see DispatchTo.
+func (a *AsyncDispatcher) Dispatch(ctx context.Context, event events.Event) error {
+	return a.DispatchTo(ctx, DefaultTopic, event)
+}
+
+// DispatchTo enqueues the given event to be dispatched by a worker to the
+// given topic. The given context is passed down to handlers, detached from its
+// cancellation, so events outlive the requests that dispatched them.
+func (a *AsyncDispatcher) DispatchTo(ctx context.Context, topic string, event events.Event) error {
+	if event == nil {
+		return fmt.Errorf("%w: cannot dispatch to topic `%s`", ErrNilEvent, topic)
+	}
+
+	a.mu.RLock()
+	if a.closed {
+		a.mu.RUnlock()
+
+		return fmt.Errorf("%w: could not dispatch event %T", ErrDispatcherClosed, event)
+	}
+
+	a.inflight.Add(1)
+	a.mu.RUnlock()
+
+	defer a.inflight.Done()
+
+	queue := a.queues[a.partition(event)]
+	item := asyncItem{ctx: context.WithoutCancel(ctx), topic: topic, event: event}
+	labels := a.labels(topic, event)
+
+	select {
+	case queue <- item:
+		a.enqueued(labels)
+
+		return nil
+	default:
+	}
+
+	switch a.opts.backpressure {
+	case Reject:
+		err := fmt.Errorf("%w: could not dispatch event %T", ErrQueueFull, event)
+		a.opts.observer.Dropped(labels, err)
+
+		return err
+	case DropNewest:
+		err := fmt.Errorf("%w: event %T dropped", ErrQueueFull, event)
+		a.opts.observer.Dropped(labels, err)
+		a.report(err)
+
+		return nil
+	case Block:
+	}
+
+	select {
+	case queue <- item:
+		a.enqueued(labels)
+
+		return nil
+	case <-a.closing:
+		return fmt.Errorf("%w: could not dispatch event %T", ErrDispatcherClosed, event)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe registers the given handler in the underlying dispatcher.
+func (a *AsyncDispatcher) Subscribe(ctx context.Context, handlerFn interface{}, opts ...SubscribeOption) (Subscription, error) {
+	return a.next.Subscribe(ctx, handlerFn, opts...)
+}
+
+// Shutdown stops accepting new events, and waits for the events already
+// enqueued to be dispatched. If the given context is done before that, its
+// error is returned and remaining events keep being dispatched in background.
+func (a *AsyncDispatcher) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.closing)
+
+		go func() {
+			a.inflight.Wait()
+
+			for i := range a.queues {
+				close(a.queues[i])
+			}
+		}()
+	}
+	a.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		a.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *AsyncDispatcher) unwrap() Dispatcher {
+	return a.next
+}
+
+func (a *AsyncDispatcher) work(queue <-chan asyncItem) {
+	defer a.workers.Done()
+
+	for item := range queue {
+		labels := a.labels(item.topic, item.event)
+		a.opts.observer.QueueDepth(observe.Labels{Component: componentAsyncDispatcher}, a.depth())
+
+		start := time.Now()
+
+		if err := a.next.DispatchTo(item.ctx, item.topic, item.event); err != nil {
+			a.opts.observer.Failed(labels, err)
+			a.report(err)
+
+			continue
+		}
+
+		a.opts.observer.Delivered(labels, time.Since(start))
+	}
+}
+
+func (a *AsyncDispatcher) enqueued(labels observe.Labels) {
+	a.opts.observer.Dispatched(labels)
+	a.opts.observer.QueueDepth(observe.Labels{Component: componentAsyncDispatcher}, a.depth())
+}
+
+// depth returns the number of events waiting in every queue.
+func (a *AsyncDispatcher) depth() int {
+	var n int
+
+	for i := range a.queues {
+		n += len(a.queues[i])
+	}
+
+	return n
+}
+
+func (a *AsyncDispatcher) labels(topic string, event events.Event) observe.Labels {
+	if !observed(a.opts.observer) {
+		return observe.Labels{}
+	}
+
+	return observe.Labels{
+		Component: componentAsyncDispatcher,
+		Topic:     topic,
+		EventType: events.TypeName(event),
+	}
+}
+
+// partition returns the index of the queue the given event must be enqueued
+// into.
+func (a *AsyncDispatcher) partition(event events.Event) int {
+	n := len(a.queues)
+	if n == 1 {
+		return 0
+	}
+
+	key := a.opts.partitionKey(event)
+	if key == "" {
+		return int(atomic.AddUint64(&a.rr, 1) % uint64(n))
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum32() % uint32(n))
+}
+
+func (a *AsyncDispatcher) report(err error) {
+	if a.opts.onError != nil {
+		a.opts.onError(err)
+	}
+}
@@ -0,0 +1,131 @@
+package dispatcher_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+func TestAsyncDispatcher(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN an async dispatcher with several workers and a slow handler", func(t *testing.T) {
+		const aggregates, perAggregate = 10, 20
+
+		dpt := dispatcher.NewAsyncDispatcher(dispatcher.NewMemoryDispatcher(), dispatcher.WithWorkers(4))
+
+		var (
+			mu       sync.Mutex
+			received = make(map[string][]int)
+		)
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, env events.Envelope) error {
+			time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			received[env.AggregateID] = append(received[env.AggregateID], env.AggregateVersion)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN events of several aggregates are dispatched AND the dispatcher is shut down THEN every event is delivered in order per aggregate", func(t *testing.T) {
+			for v := 1; v <= perAggregate; v++ {
+				for a := 0; a < aggregates; a++ {
+					env := events.Envelope{AggregateID: fmt.Sprint("agg-", a), AggregateVersion: v, Payload: privateMessage{}}
+					require.NoError(t, dpt.Dispatch(ctx, env))
+				}
+			}
+
+			require.NoError(t, dpt.Shutdown(ctx))
+			require.Len(t, received, aggregates)
+
+			for id, versions := range received {
+				require.Len(t, versions, perAggregate, id)
+
+				for i := range versions {
+					require.Equal(t, i+1, versions[i], id)
+				}
+			}
+		})
+
+		t.Run("WHEN dispatching after shutdown THEN it fails", func(t *testing.T) {
+			require.ErrorIs(t, dpt.Dispatch(ctx, privateMessage{}), dispatcher.ErrDispatcherClosed)
+		})
+	})
+
+	t.Run("GIVEN async dispatchers with a full queue", func(t *testing.T) {
+		setup := func(t *testing.T, opts ...dispatcher.AsyncOption) *dispatcher.AsyncDispatcher {
+			release := make(chan struct{})
+			started := make(chan struct{}, 1)
+			opts = append(opts, dispatcher.WithQueueSize(1))
+			dpt := dispatcher.NewAsyncDispatcher(dispatcher.NewMemoryDispatcher(), opts...)
+
+			_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+				started <- struct{}{}
+				<-release
+
+				return nil
+			})
+			require.NoError(t, err)
+
+			// first one is taken by the worker, second one fills the queue.
+			require.NoError(t, dpt.Dispatch(ctx, "busy"))
+			<-started
+			require.NoError(t, dpt.Dispatch(ctx, "queued"))
+
+			t.Cleanup(func() {
+				close(release)
+				require.NoError(t, dpt.Shutdown(ctx))
+			})
+
+			return dpt
+		}
+
+		t.Run("WHEN dispatching with the reject policy THEN it fails with ErrQueueFull", func(t *testing.T) {
+			dpt := setup(t, dispatcher.WithBackpressure(dispatcher.Reject))
+
+			require.ErrorIs(t, dpt.Dispatch(ctx, "rejected"), dispatcher.ErrQueueFull)
+		})
+
+		t.Run("WHEN dispatching with the drop policy THEN it succeeds AND the drop is reported", func(t *testing.T) {
+			reported := make(chan error, 1)
+			dpt := setup(t,
+				dispatcher.WithBackpressure(dispatcher.DropNewest),
+				dispatcher.WithAsyncErrorCallback(func(err error) {
+					reported <- err
+				}),
+			)
+
+			require.NoError(t, dpt.Dispatch(ctx, "dropped"))
+			require.ErrorIs(t, <-reported, dispatcher.ErrQueueFull)
+		})
+
+		t.Run("WHEN dispatching with the block policy THEN it waits until the given context is done", func(t *testing.T) {
+			dpt := setup(t)
+
+			tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+
+			require.ErrorIs(t, dpt.Dispatch(tctx, "blocked"), context.DeadlineExceeded)
+		})
+
+		t.Run("WHEN shutting down with a context that expires before the queue is drained THEN shutdown fails", func(t *testing.T) {
+			dpt := setup(t)
+
+			tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+
+			require.ErrorIs(t, dpt.Shutdown(tctx), context.DeadlineExceeded)
+		})
+	})
+}
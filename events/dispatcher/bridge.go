@@ -0,0 +1,155 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+type dispatcherSink struct {
+	d      Dispatcher
+	closed bool
+	mu     sync.RWMutex
+}
+
+// AsSink adapts the given dispatcher into a sink, so it can be placed at the
+// end of a sink pipeline. Each written message is dispatched synchronously,
+// and the error returned by Dispatch, if any, is returned by Write.
+//
+// The sink does not own the dispatcher: closing the sink only stops accepting
+// messages, while the dispatcher and its subscribers remain untouched.
+func AsSink(d Dispatcher) drain.Sink[events.Event] {
+	return &dispatcherSink{d: d}
+}
+
+func (s *dispatcherSink) Write(message events.Event) error {
+	// the lock is not held while dispatching, so handlers may close the sink.
+	s.mu.RLock()
+	closed := s.closed
+	s.mu.RUnlock()
+
+	if closed {
+		return fmt.Errorf("%w: dispatcher sink could not write message %T", drain.ErrSinkClosed, message)
+	}
+
+	if err := s.d.Dispatch(context.Background(), message); err != nil {
+		return fmt.Errorf("%w: dispatcher sink could not dispatch message %T", err, message)
+	}
+
+	return nil
+}
+
+func (s *dispatcherSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("%w: dispatcher sink could not close", drain.ErrSinkClosed)
+	}
+
+	s.closed = true
+
+	return nil
+}
+
+// ForwardTo subscribes to every event dispatched by the given dispatcher that
+// matches the given filter, nil meaning every event, and writes them into the
+// given sink. Dispatched envelopes are forwarded as is, so sinks receive the
+// whole envelope rather than its payload. Sink failures are reported as
+// handler failures, see ErrorPolicy.
+//
+// Events are written synchronously, by the goroutine delivering them, so they
+// reach the sink in the order they are delivered, and slow sinks slow down
+// dispatching. Place an asynchronous sink, such as a queue sink, in front of
+// slow sinks.
+//
+// The forwarding owns the sink: once the returned subscription ends, the sink
+// is closed as well, flushing pipelines such as queue sinks. Deliveries still
+// writing into the sink are waited for before closing it.
+func ForwardTo(d Dispatcher, sink drain.Sink[events.Event], filter drain.FilterFn[events.Event], opts ...SubscribeOption) (Subscription, error) {
+	fwd := &forwardSubscription{sink: sink}
+	o := newSubscribeOptions(opts...)
+
+	sub, err := On(d, func(ctx context.Context, event events.Event) error {
+		if original, ok := dispatchedEvent(ctx); ok {
+			event = original
+		} else if env, isEnv := events.EnvelopeFromContext(ctx); isEnv {
+			event = env
+		}
+
+		if filter != nil && !filter(event) {
+			return nil
+		}
+
+		return fwd.write(event)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not forward events to sink", err)
+	}
+
+	fwd.Subscription = sub
+
+	// Subscriptions may also end because their context is done, see UntilDone.
+	if o.lifetime != nil {
+		stop := context.AfterFunc(o.lifetime, func() {
+			_ = fwd.Unsubscribe()
+		})
+
+		fwd.mu.Lock()
+		fwd.stop = stop
+		fwd.mu.Unlock()
+	}
+
+	return fwd, nil
+}
+
+type forwardSubscription struct {
+	Subscription
+	sink   drain.Sink[events.Event]
+	stop   func() bool
+	closed bool
+	mu     sync.RWMutex
+	once   sync.Once
+	err    error
+}
+
+// write writes the given event into the sink, unless the forwarding already
+// ended. Unsubscribe waits for in-flight writes before closing the sink.
+func (f *forwardSubscription) write(event events.Event) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.closed {
+		return nil
+	}
+
+	return f.sink.Write(event)
+}
+
+func (f *forwardSubscription) Unsubscribe() error {
+	f.once.Do(func() {
+		errU := f.Subscription.Unsubscribe()
+
+		f.mu.Lock()
+		f.closed = true
+		stop := f.stop
+		f.mu.Unlock()
+
+		if stop != nil {
+			stop()
+		}
+
+		var errC error
+		if err := f.sink.Close(); err != nil {
+			errC = fmt.Errorf("%w: could not close forwarding sink", err)
+		}
+
+		f.err = errors.Join(errU, errC)
+	})
+
+	return f.err
+}
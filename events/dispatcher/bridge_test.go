@@ -0,0 +1,274 @@
+package dispatcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestAsSink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN a fail-fast dispatcher adapted as a sink", func(t *testing.T) {
+		errRejected := errors.New("rejected")
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithErrorPolicy(dispatcher.FailFast))
+		sink := dispatcher.AsSink(dpt)
+
+		var received []string
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			if msg == "reject" {
+				return errRejected
+			}
+
+			received = append(received, msg)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN writing messages THEN they are dispatched in order", func(t *testing.T) {
+			for _, msg := range []string{"a", "b", "c"} {
+				require.NoError(t, sink.Write(msg))
+			}
+
+			require.Equal(t, []string{"a", "b", "c"}, received)
+		})
+
+		t.Run("WHEN a handler fails THEN the write fails with its error", func(t *testing.T) {
+			require.ErrorIs(t, sink.Write("reject"), errRejected)
+		})
+
+		t.Run("WHEN the sink is closed THEN writes fail AND the dispatcher keeps working", func(t *testing.T) {
+			require.NoError(t, sink.Close())
+			require.ErrorIs(t, sink.Write("d"), drain.ErrSinkClosed)
+
+			require.NoError(t, dpt.Dispatch(ctx, "e"))
+			require.Equal(t, []string{"a", "b", "c", "e"}, received)
+		})
+	})
+	t.Run("GIVEN a dispatcher adapted as a sink AND a handler closing the sink", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+		sink := dispatcher.AsSink(dpt)
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			return sink.Close()
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN writing a message THEN it does not deadlock AND later writes fail", func(t *testing.T) {
+			written := make(chan error, 1)
+
+			go func() {
+				written <- sink.Write("close")
+			}()
+
+			select {
+			case wErr := <-written:
+				require.NoError(t, wErr)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "write deadlocked")
+			}
+
+			require.ErrorIs(t, sink.Write("late"), drain.ErrSinkClosed)
+		})
+	})
+}
+
+func TestForwardTo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN a dispatcher forwarding string events and envelopes into a sink", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithErrorPolicy(dispatcher.CollectErrors))
+		sink := &collectSink{}
+
+		sub, err := dispatcher.ForwardTo(dpt, sink, func(event events.Event) bool {
+			_, isString := events.Unwrap(event).(string)
+
+			return isString
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN events are dispatched THEN matching ones are written in order AND envelopes are kept", func(t *testing.T) {
+			env := events.Wrap(ctx, "b")
+
+			require.NoError(t, dpt.Dispatch(ctx, "a"))
+			require.NoError(t, dpt.Dispatch(ctx, privateMessage{}))
+			require.NoError(t, dpt.Dispatch(ctx, env))
+
+			require.Equal(t, []events.Event{"a", env}, sink.messages())
+		})
+
+		t.Run("WHEN the sink fails THEN dispatch returns its error", func(t *testing.T) {
+			errSink := errors.New("sink failed")
+			sink.fail(errSink)
+
+			require.ErrorIs(t, dpt.Dispatch(ctx, "c"), errSink)
+			sink.fail(nil)
+		})
+
+		t.Run("WHEN unsubscribing THEN the sink is closed AND no more events are forwarded", func(t *testing.T) {
+			require.NoError(t, sub.Unsubscribe())
+			require.True(t, sink.isClosed())
+
+			require.NoError(t, dpt.Dispatch(ctx, "d"))
+			require.Len(t, sink.messages(), 2)
+		})
+	})
+
+	t.Run("GIVEN a forwarding bound to a context WHEN the context is cancelled THEN the sink is closed", func(t *testing.T) {
+		scope, cancel := context.WithCancel(ctx)
+		sink := &collectSink{closed: make(chan struct{})}
+
+		_, err := dispatcher.ForwardTo(dispatcher.NewMemoryDispatcher(), sink, nil, dispatcher.UntilDone(scope))
+		require.NoError(t, err)
+
+		cancel()
+		<-sink.closed
+	})
+
+	t.Run("GIVEN a forwarding into a slow sink", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+		sink := &slowSink{started: make(chan struct{}), release: make(chan struct{})}
+
+		sub, err := dispatcher.ForwardTo(dpt, sink, nil)
+		require.NoError(t, err)
+
+		t.Run("WHEN unsubscribing while an event is being written THEN the sink is closed once the write completes", func(t *testing.T) {
+			dispatched := make(chan error)
+			unsubscribed := make(chan error)
+
+			go func() {
+				dispatched <- dpt.Dispatch(ctx, "pending")
+			}()
+
+			<-sink.started
+
+			go func() {
+				unsubscribed <- sub.Unsubscribe()
+			}()
+
+			select {
+			case <-unsubscribed:
+				t.Fatal("unsubscribed before the in-flight write completed")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			close(sink.release)
+
+			require.NoError(t, <-dispatched)
+			require.NoError(t, <-unsubscribed)
+			require.True(t, sink.isClosed())
+			require.False(t, sink.wroteAfterClose())
+		})
+	})
+}
+
+// slowSink is a sink whose writes block until released, recording whether
+// any of them completed after the sink was closed.
+type slowSink struct {
+	started   chan struct{}
+	release   chan struct{}
+	startOnce sync.Once
+	mu        sync.Mutex
+	closed    bool
+	late      bool
+}
+
+func (s *slowSink) Write(events.Event) error {
+	s.startOnce.Do(func() { close(s.started) })
+	<-s.release
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.late = s.late || s.closed
+
+	return nil
+}
+
+func (s *slowSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+
+	return nil
+}
+
+func (s *slowSink) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+func (s *slowSink) wroteAfterClose() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.late
+}
+
+// collectSink is a thread-safe sink that records written messages.
+type collectSink struct {
+	mu       sync.Mutex
+	received []events.Event
+	err      error
+	done     bool
+	closed   chan struct{}
+}
+
+func (c *collectSink) Write(message events.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	c.received = append(c.received, message)
+
+	return nil
+}
+
+func (c *collectSink) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.done = true
+
+	if c.closed != nil {
+		close(c.closed)
+	}
+
+	return nil
+}
+
+func (c *collectSink) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.err = err
+}
+
+func (c *collectSink) messages() []events.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]events.Event{}, c.received...)
+}
+
+func (c *collectSink) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.done
+}
@@ -0,0 +1,30 @@
+package dispatcher
+
+import (
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+// WithCodec sets the functions used to encode events before publishing them
+// to the broker, and to decode them back when received. Required by brokers
+// that move events out of the process, as they can only move bytes.
+func WithCodec(marshal drain.Marshaller[events.Event], unmarshal drain.Unmarshaller[events.Event]) Option {
+	return func(o *options) {
+		o.marshal = marshal
+		o.unmarshal = unmarshal
+	}
+}
+
+// WithJSONCodec encodes events as JSON, using the names under which their
+// types are registered in the given registry to decode them back. See
+// drain.NewJSONMarshaller.
+func WithJSONCodec(registry *events.Registry) Option {
+	return WithCodec(drain.NewJSONMarshaller(registry), drain.NewJSONUnmarshaller(registry))
+}
+
+// WithProtoCodec encodes events as protobuf messages, using the names under
+// which their types are registered in the given registry to decode them back.
+// See drain.NewProtoMarshaller.
+func WithProtoCodec(registry *events.Registry) Option {
+	return WithCodec(drain.NewProtoMarshaller(registry), drain.NewProtoUnmarshaller(registry))
+}
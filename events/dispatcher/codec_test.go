@@ -0,0 +1,132 @@
+package dispatcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/botchris/go-pubsub"
+	"github.com/botchris/go-pubsub/provider/memory"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN a dispatcher over a custom broker", func(t *testing.T) {
+		broker := &recordingBroker{Broker: memory.NewBroker()}
+		dpt := dispatcher.New(broker)
+
+		var received []string
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			received = append(received, msg)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching an event THEN it goes through the broker", func(t *testing.T) {
+			require.NoError(t, dpt.DispatchTo(ctx, dispatcher.DefaultTopic, "test"))
+			require.Equal(t, []interface{}{"test"}, broker.messages())
+			require.Equal(t, []string{"test"}, received)
+		})
+	})
+
+	t.Run("GIVEN a dispatcher with a JSON codec over a custom broker", func(t *testing.T) {
+		reg := events.NewRegistry()
+		require.NoError(t, reg.RegisterName("test.private", privateMessage{}))
+
+		broker := &recordingBroker{Broker: memory.NewBroker()}
+		dpt := dispatcher.New(broker, dispatcher.WithJSONCodec(reg))
+
+		var (
+			payloads  []privateMessage
+			envelopes []events.Envelope
+		)
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg privateMessage) error {
+			payloads = append(payloads, msg)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, func(ctx context.Context, env events.Envelope) error {
+			envelopes = append(envelopes, env)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching an envelope THEN bytes are published AND handlers receive the decoded event", func(t *testing.T) {
+			msg := privateMessage{Payload: "hello"}
+			env := events.Wrap(ctx, msg)
+
+			require.NoError(t, dpt.Dispatch(ctx, env))
+
+			published := broker.messages()
+			require.Len(t, published, 1)
+			require.IsType(t, []byte{}, published[0])
+
+			require.Equal(t, []privateMessage{msg}, payloads)
+			require.Len(t, envelopes, 1)
+			require.Equal(t, env.EventID, envelopes[0].EventID)
+			require.Equal(t, "test.private", envelopes[0].EventType)
+		})
+
+		t.Run("WHEN dispatching an unregistered event THEN it fails", func(t *testing.T) {
+			require.ErrorIs(t, dpt.Dispatch(ctx, "unregistered"), events.ErrEventNotRegistered)
+		})
+	})
+
+	t.Run("GIVEN two dispatchers sharing a broker, one with a failing handler AND the other with a succeeding one", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		broker := memory.NewBroker()
+		failing := dispatcher.New(broker, dispatcher.WithErrorPolicy(dispatcher.CollectErrors))
+		succeeding := dispatcher.New(broker, dispatcher.WithErrorPolicy(dispatcher.CollectErrors))
+
+		_, err := dispatcher.On(failing, func(ctx context.Context, msg string) error {
+			return errFailed
+		})
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(succeeding, func(ctx context.Context, msg string) error {
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN each one dispatches THEN each one only reports the outcome of its own handlers", func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				require.ErrorIs(t, failing.Dispatch(ctx, "x"), errFailed)
+				require.NoError(t, succeeding.Dispatch(ctx, "x"))
+			}
+		})
+	})
+}
+
+// recordingBroker is a broker that records every published message.
+type recordingBroker struct {
+	pubsub.Broker
+
+	mu        sync.Mutex
+	published []interface{}
+}
+
+func (r *recordingBroker) Publish(ctx context.Context, topic pubsub.Topic, m interface{}) error {
+	r.mu.Lock()
+	r.published = append(r.published, m)
+	r.mu.Unlock()
+
+	return r.Broker.Publish(ctx, topic, m)
+}
+
+func (r *recordingBroker) messages() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]interface{}{}, r.published...)
+}
@@ -0,0 +1,140 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+// Dead-letter related errors.
+var (
+	// ErrHandlerNotFound is returned when redelivering a dead letter whose
+	// handler is not subscribed, or does not expect its event.
+	ErrHandlerNotFound = errors.New("handler not found")
+
+	// ErrAmbiguousHandler is returned when redelivering a dead letter whose
+	// handler name matches several subscribed handlers. Use Named to tell
+	// them apart.
+	ErrAmbiguousHandler = errors.New("ambiguous handler")
+
+	// ErrRedeliveryUnsupported is returned when redelivering dead letters
+	// through dispatchers not built by this package.
+	ErrRedeliveryUnsupported = errors.New("redelivery unsupported")
+)
+
+// DeadLetter describes an event a handler failed to handle, once every retry,
+// if any, was exhausted.
+type DeadLetter struct {
+	// Handler identifies the failing handler, by the name given with Named
+	// if any, or else by the name of its function, see HandlerInfo.Name.
+	Handler string
+
+	// Topic is the topic the event was dispatched to.
+	Topic string
+
+	// Event is the dispatched event. Envelopes are kept as is.
+	Event events.Event
+
+	// Err is the last error returned by the handler.
+	Err error
+
+	// Attempts is the number of times the handler was invoked, see WithRetry.
+	Attempts int
+}
+
+// WithDeadLetters writes every failed delivery into the given sink, once
+// retries of the failing handler, if any, are exhausted. Failures are still
+// handled according to the error policy. Failures to write into the sink are
+// reported to the error callback, see WithErrorCallback.
+//
+// Dead letters can be given back to their handler with Redeliver.
+func WithDeadLetters(sink drain.Sink[DeadLetter]) Option {
+	return func(o *options) {
+		o.deadLetters = sink
+	}
+}
+
+// Redeliver delivers the event of the given dead letter again, only to the
+// handler it failed on, and returns the handler failure if any. As with any
+// other delivery, the handler is retried if so configured, and a new dead
+// letter is written if it fails again.
+func Redeliver(ctx context.Context, d Dispatcher, letter DeadLetter) error {
+	for {
+		w, ok := d.(wrapper)
+		if !ok {
+			break
+		}
+
+		d = w.unwrap()
+	}
+
+	b, ok := d.(*brokerDispatcher)
+	if !ok {
+		return fmt.Errorf("%w: dispatcher %T", ErrRedeliveryUnsupported, d)
+	}
+
+	return b.router.redeliver(ctx, letter)
+}
+
+// wrapper is implemented by the dispatchers of this package built on top of
+// another one, such as channels and asynchronous dispatchers.
+type wrapper interface {
+	unwrap() Dispatcher
+}
+
+func (r *router) redeliver(ctx context.Context, letter DeadLetter) error {
+	ctx, payload := deliveryContext(contextWithTopic(ctx, letter.Topic), letter.Event)
+	_, isEnvelope := letter.Event.(events.Envelope)
+
+	var found *route
+
+	for _, rt := range r.current().routes {
+		if rt.name() != letter.Handler || !matchTopic(rt.topic, letter.Topic) {
+			continue
+		}
+
+		if rt.info.EventType == envelopeType && !isEnvelope {
+			continue
+		}
+
+		if rt.info.EventType != envelopeType && !rt.accepts(reflect.TypeOf(payload)) {
+			continue
+		}
+
+		if found != nil {
+			return fmt.Errorf("%w: `%s` on topic `%s`", ErrAmbiguousHandler, letter.Handler, letter.Topic)
+		}
+
+		found = rt
+	}
+
+	if found == nil {
+		return fmt.Errorf("%w: `%s` on topic `%s` for event %T", ErrHandlerNotFound, letter.Handler, letter.Topic, letter.Event)
+	}
+
+	return r.invoke(ctx, letter.Topic, found, letter.Event, payload)
+}
+
+func (r *router) deadLetter(letter DeadLetter) {
+	if err := r.deadLetters.Write(letter); err != nil && r.onError != nil {
+		r.onError(fmt.Errorf("%w: could not write dead letter of handler `%s`", err, letter.Handler))
+	}
+}
+
+type attemptsKey struct{}
+
+func contextWithAttempts(ctx context.Context, attempts *int) context.Context {
+	return context.WithValue(ctx, attemptsKey{}, attempts)
+}
+
+// recordAttempt records the number of times a handler was invoked so far, so
+// dead letters can report it.
+func recordAttempt(ctx context.Context, attempt int) {
+	if attempts, ok := ctx.Value(attemptsKey{}).(*int); ok {
+		*attempts = attempt
+	}
+}
@@ -0,0 +1,161 @@
+package dispatcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestDeadLetters(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN a dispatcher with a dead-letter sink AND a retried handler failing on some events", func(t *testing.T) {
+		errRejected := errors.New("rejected")
+		letters := &deadLetterSink{}
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithDeadLetters(letters))
+
+		var (
+			mu       sync.Mutex
+			handled  []string
+			failures = map[string]bool{"bad": true}
+		)
+
+		strategy := drain.NewExponentialBackoff[events.Event](drain.ExponentialBackoffConfig{
+			Base:   time.Millisecond,
+			Factor: time.Millisecond,
+			Max:    time.Millisecond,
+		})
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if failures[msg] {
+				return errRejected
+			}
+
+			handled = append(handled, msg)
+
+			return nil
+		}, dispatcher.Named("processor"), dispatcher.WithRetry(strategy, 3))
+		require.NoError(t, err)
+
+		var others []string
+
+		_, err = dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			others = append(others, msg)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching a failing event THEN a dead letter is written after every attempt AND other handlers are not affected", func(t *testing.T) {
+			env := events.Wrap(ctx, "bad")
+
+			require.NoError(t, dpt.Dispatch(ctx, "good"))
+			require.NoError(t, dpt.Dispatch(ctx, env))
+
+			require.Equal(t, []string{"good"}, handled)
+			require.Equal(t, []string{"good", "bad"}, others)
+
+			written := letters.letters()
+			require.Len(t, written, 1)
+			require.Equal(t, "processor", written[0].Handler)
+			require.Equal(t, dispatcher.DefaultTopic, written[0].Topic)
+			require.Equal(t, env, written[0].Event)
+			require.ErrorIs(t, written[0].Err, errRejected)
+			require.Equal(t, 3, written[0].Attempts)
+		})
+
+		t.Run("WHEN redelivering the dead letter after the cause is fixed THEN only its handler receives it", func(t *testing.T) {
+			mu.Lock()
+			failures["bad"] = false
+			mu.Unlock()
+
+			require.NoError(t, dispatcher.Redeliver(ctx, dpt, letters.letters()[0]))
+			require.Equal(t, []string{"good", "bad"}, handled)
+			require.Equal(t, []string{"good", "bad"}, others)
+		})
+
+		t.Run("WHEN redelivering to an unknown handler THEN it fails", func(t *testing.T) {
+			letter := letters.letters()[0]
+			letter.Handler = "unknown"
+
+			require.ErrorIs(t, dispatcher.Redeliver(ctx, dpt, letter), dispatcher.ErrHandlerNotFound)
+		})
+	})
+
+	t.Run("GIVEN a dispatcher with a dead-letter sink AND a handler without retries WHEN it fails THEN a dead letter of a single attempt is written", func(t *testing.T) {
+		letters := &deadLetterSink{}
+		dpt := dispatcher.NewMemoryDispatcher(
+			dispatcher.WithDeadLetters(letters),
+			dispatcher.WithErrorPolicy(dispatcher.FailFast),
+		)
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg privateMessage) error {
+			return errors.New("failed")
+		})
+		require.NoError(t, err)
+
+		require.Error(t, dpt.DispatchTo(ctx, dispatcher.DefaultTopic, privateMessage{Payload: "x"}))
+
+		written := letters.letters()
+		require.Len(t, written, 1)
+		require.Equal(t, 1, written[0].Attempts)
+		require.Equal(t, privateMessage{Payload: "x"}, written[0].Event)
+		require.Contains(t, written[0].Handler, "TestDeadLetters")
+
+		t.Run("WHEN redelivering it AND the handler fails again THEN the failure is returned AND a new dead letter is written", func(t *testing.T) {
+			var hErr *dispatcher.HandlerError
+
+			require.ErrorAs(t, dispatcher.Redeliver(ctx, dpt, written[0]), &hErr)
+			require.Len(t, letters.letters(), 2)
+		})
+
+		t.Run("WHEN redelivering it through a channel of an asynchronous dispatcher THEN it reaches the handler", func(t *testing.T) {
+			async := dispatcher.NewAsyncDispatcher(dpt)
+			defer func() { require.NoError(t, async.Shutdown(ctx)) }()
+
+			channel, err := dispatcher.Channel(async, dispatcher.DefaultTopic)
+			require.NoError(t, err)
+
+			var hErr *dispatcher.HandlerError
+
+			require.ErrorAs(t, dispatcher.Redeliver(ctx, channel, written[0]), &hErr)
+			require.Len(t, letters.letters(), 3)
+		})
+	})
+}
+
+// deadLetterSink is a thread-safe sink that records written dead letters.
+type deadLetterSink struct {
+	mu      sync.Mutex
+	written []dispatcher.DeadLetter
+}
+
+func (s *deadLetterSink) Write(letter dispatcher.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.written = append(s.written, letter)
+
+	return nil
+}
+
+func (s *deadLetterSink) Close() error {
+	return nil
+}
+
+func (s *deadLetterSink) letters() []dispatcher.DeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]dispatcher.DeadLetter{}, s.written...)
+}
@@ -1,5 +1,5 @@
 // Package dispatcher provides a simple mechanism for dispatching domain events
-// locally using a simple in-memory broker.
+// through a pubsub broker, an in-memory one by default.
 //
 // Dispatcher must only be used to communicate between different parts of the
 // same application or "Bounded Context". For communicating between different
@@ -11,28 +11,50 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
+	"sync"
 
 	"github.com/botchris/go-pubsub"
 	"github.com/botchris/go-pubsub/provider/memory"
 	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+	"github.com/tangelo-labs/go-domain/events/observe"
 )
 
-// ErrInvalidHandlerFunc returned when subscribing with an invalid handler function.
-var ErrInvalidHandlerFunc = errors.New("invalid handler function")
+// Dispatcher related errors.
+var (
+	// ErrInvalidHandlerFunc returned when subscribing with an invalid handler
+	// function.
+	ErrInvalidHandlerFunc = errors.New("invalid handler function")
+
+	// ErrInvalidTopic returned when dispatching to a wildcard topic, or to a
+	// topic other than the one of a channel, and when binding a channel to a
+	// wildcard topic, see Channel.
+	ErrInvalidTopic = errors.New("invalid topic")
+
+	// ErrNilEvent returned when dispatching a nil event.
+	ErrNilEvent = errors.New("nil event")
+)
 
 var (
-	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
-	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	contextType  = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	envelopeType = reflect.TypeOf(events.Envelope{})
 )
 
 // Dispatcher defines a component capable of registering listeners and
 // dispatching events to them.
 type Dispatcher interface {
-	// Dispatch dispatches an event to active subscribers. If this method fails,
-	// means that the event was not delivered to any listener and could be
-	// retried.
+	// Dispatch dispatches an event to active subscribers. Whether failures of
+	// subscribers are returned by this method depends on the configured
+	// ErrorPolicy, in which case they are reported as *HandlerError.
 	Dispatch(ctx context.Context, event events.Event) error
 
+	// DispatchTo dispatches an event to the subscribers of the given topic, as
+	// well as to wildcard subscribers matching it. Dispatch is equivalent to
+	// dispatching to DefaultTopic. See OnTopic for subscribing to topics.
+	DispatchTo(ctx context.Context, topic string, event events.Event) error
+
 	// Subscribe registers a handler function to react on specific events.
 	// The handler function must have the following signature:
 	//
@@ -40,50 +62,170 @@ type Dispatcher interface {
 	//
 	// Where <T> is the type of the event to be handled. This type cannot be
 	// a pointer. This enforces the immutability of the events when dispatched.
+	// When <T> is an interface type, the handler receives every event whose
+	// type implements it, so events.Event catches all events.
 	//
 	// Examples:
 	//
 	// 	func(ctx context.Context, event string) error
 	// 	func(ctx context.Context, event myMessage) error
-	Subscribe(ctx context.Context, handlerFn interface{}) error
+	// 	func(ctx context.Context, event OrderEvent) error
+	// 	func(ctx context.Context, event events.Event) error
+	//
+	// When an events.Envelope is dispatched, handlers expecting the type of its
+	// payload receive the payload, and handlers expecting events.Envelope
+	// receive the envelope itself. In both cases, the envelope can be accessed
+	// using events.EnvelopeFromContext.
+	//
+	// Handlers are invoked in the same order they were subscribed, unless
+	// told otherwise with WithPriority, Before or After. The handlers
+	// matching a topic and event type, including interface-typed ones which
+	// require an `Implements` check per handler, are resolved the first time
+	// such an event is dispatched, and remembered until subscriptions change.
+	// Hence, dispatching costs proportionally to the number of matching
+	// handlers, while subscribing or unsubscribing resets that resolution.
+	//
+	// The returned Subscription can be used to remove the handler later on.
+	// See UntilDone for binding the subscription to a context instead.
+	Subscribe(ctx context.Context, handlerFn interface{}, opts ...SubscribeOption) (Subscription, error)
+}
+
+// Option configures a dispatcher.
+type Option func(*options)
+
+type options struct {
+	middlewares []Middleware
+	errorPolicy ErrorPolicy
+	onError     func(error)
+	marshal     drain.Marshaller[events.Event]
+	unmarshal   drain.Unmarshaller[events.Event]
+	deadLetters drain.Sink[DeadLetter]
+	observer    observe.Observer
 }
 
-type memoryDispatcher struct {
-	topic  pubsub.Topic
-	broker pubsub.Broker
+// WithMiddleware appends the given middlewares to the chain applied to every
+// Dispatch call and handler invocation. Middlewares are invoked in the order
+// they are given, the first one being the outermost.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, mws...)
+	}
+}
+
+type brokerDispatcher struct {
+	broker   pubsub.Broker
+	router   *router
+	opts     options
+	dispatch HandlerFunc
+	topics   map[string]struct{}
+	mu       sync.Mutex
 }
 
 // NewMemoryDispatcher builds a dispatcher that moves events using local memory
 // in a thread-safe way.
-func NewMemoryDispatcher() Dispatcher {
-	return &memoryDispatcher{
-		topic:  "default",
-		broker: memory.NewBroker(),
+func NewMemoryDispatcher(opts ...Option) Dispatcher {
+	return New(memory.NewBroker(), opts...)
+}
+
+// New builds a dispatcher that moves events through the given broker, each
+// topic of the dispatcher being a topic of the broker. Brokers that move
+// events out of the process need a codec, see WithCodec.
+//
+// Handler failures can only be returned by Dispatch when the broker delivers
+// events synchronously within the same process, as the in-memory broker
+// does. Otherwise, use WithErrorCallback to be notified about them.
+func New(broker pubsub.Broker, opts ...Option) Dispatcher {
+	b := &brokerDispatcher{
+		broker: broker,
+		topics: make(map[string]struct{}),
+	}
+
+	for i := range opts {
+		opts[i](&b.opts)
+	}
+
+	if b.opts.observer == nil {
+		b.opts.observer = observe.Nop{}
+	}
+
+	b.router = &router{
+		policy:      b.opts.errorPolicy,
+		onError:     b.opts.onError,
+		deadLetters: b.opts.deadLetters,
+		observer:    b.opts.observer,
+		observed:    observed(b.opts.observer),
 	}
+
+	b.dispatch = b.wrap(HandlerInfo{}, func(ctx context.Context, event events.Event) error {
+		topic := TopicFromContext(ctx)
+		if err := b.ensureTopic(ctx, topic); err != nil {
+			return err
+		}
+
+		var message interface{} = event
+
+		if b.opts.marshal != nil {
+			raw, err := b.opts.marshal(event)
+			if err != nil {
+				return fmt.Errorf("%w: could not encode event %T", err, event)
+			}
+
+			message = raw
+		}
+
+		res := &deliveryResult{owner: b}
+
+		if err := b.broker.Publish(contextWithDeliveryResult(ctx, res), pubsub.Topic(topic), message); err != nil {
+			return fmt.Errorf("%w: could not publish event %T to topic `%s`", err, event, topic)
+		}
+
+		return res.result()
+	})
+
+	return b
 }
 
-func (m *memoryDispatcher) Dispatch(ctx context.Context, event events.Event) error {
-	return m.broker.Publish(ctx, m.topic, event)
+func (b *brokerDispatcher) Dispatch(ctx context.Context, event events.Event) error {
+	return b.DispatchTo(ctx, DefaultTopic, event)
 }
 
-func (m *memoryDispatcher) Subscribe(ctx context.Context, handlerFn interface{}) error {
-	handlerType, err := m.validateHandler(handlerFn)
+func (b *brokerDispatcher) DispatchTo(ctx context.Context, topic string, event events.Event) error {
+	if event == nil {
+		return fmt.Errorf("%w: cannot dispatch to topic `%s`", ErrNilEvent, topic)
+	}
+
+	if isWildcard(topic) {
+		return fmt.Errorf("%w: cannot dispatch to wildcard topic `%s`", ErrInvalidTopic, topic)
+	}
+
+	if b.router.observed {
+		b.opts.observer.Dispatched(observe.Labels{
+			Component: componentDispatcher,
+			Topic:     topic,
+			EventType: events.TypeName(event),
+		})
+	}
+
+	return b.dispatch(contextWithTopic(ctx, topic), event)
+}
+
+func (b *brokerDispatcher) Subscribe(ctx context.Context, handlerFn interface{}, opts ...SubscribeOption) (Subscription, error) {
+	handlerType, err := b.validateHandler(handlerFn)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	handler := pubsub.NewHandler(func(ctx context.Context, t pubsub.Topic, catchAll interface{}) error {
-		inputType := reflect.TypeOf(catchAll)
-		if inputType != handlerType.In(1) {
-			return nil
-		}
+	fnValue := reflect.ValueOf(handlerFn)
+	info := HandlerInfo{
+		Name:      runtime.FuncForPC(fnValue.Pointer()).Name(),
+		EventType: handlerType.In(1),
+	}
 
-		inputValue := reflect.ValueOf(catchAll)
-		output := reflect.ValueOf(handlerFn).
-			Call([]reflect.Value{
-				reflect.ValueOf(ctx),
-				inputValue,
-			})
+	return b.subscribe(ctx, info, newSubscribeOptions(opts...), func(ctx context.Context, event events.Event) error {
+		output := fnValue.Call([]reflect.Value{
+			reflect.ValueOf(ctx),
+			reflect.ValueOf(event),
+		})
 
 		if output[0].IsNil() {
 			return nil
@@ -91,13 +233,109 @@ func (m *memoryDispatcher) Subscribe(ctx context.Context, handlerFn interface{})
 
 		return output[0].Interface().(error)
 	})
+}
+
+// subscribe registers the given function to be invoked with events of the
+// type described by the given handler info.
+func (b *brokerDispatcher) subscribe(ctx context.Context, info HandlerInfo, opts subscribeOptions, fn HandlerFunc) (Subscription, error) {
+	if !isWildcard(opts.topic) {
+		if err := b.ensureTopic(ctx, opts.topic); err != nil {
+			return nil, err
+		}
+	}
+
+	invoke := b.wrap(info, fn)
+	if opts.retry != nil {
+		handler := info.Name
+		if opts.order.name != "" {
+			handler = opts.order.name
+		}
+
+		invoke = retrying(invoke, opts.retry, opts.maxAttempts, func(ctx context.Context, event events.Event, attempt int) {
+			if b.router.observed {
+				b.opts.observer.Retried(handlerLabels(ctx, handler, event), attempt)
+			}
+		})
+	}
+
+	rt, err := b.router.add(opts.topic, opts.order, info, invoke)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSubscription(rt.id, func() error {
+		b.router.remove(rt)
+
+		return nil
+	}, opts), nil
+}
+
+// ensureTopic subscribes the router to the given topic of the broker, unless
+// it is already subscribed. Handlers are not subscribed to the broker
+// individually, as it delivers messages in no particular order. Instead, a
+// single broker subscription per topic delivers events to the router, which
+// keeps handlers in order.
+func (b *brokerDispatcher) ensureTopic(ctx context.Context, topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.topics[topic]; ok {
+		return nil
+	}
+
+	handler := pubsub.NewHandler(func(ctx context.Context, _ pubsub.Topic, message interface{}) error {
+		err := b.receive(contextWithTopic(ctx, topic), topic, message)
+
+		if res, ok := deliveryResultFromContext(ctx); ok {
+			res.record(b, err)
+		}
+
+		return err
+	})
+
+	if _, err := b.broker.Subscribe(ctx, pubsub.Topic(topic), handler); err != nil {
+		return fmt.Errorf("%w: could not subscribe to topic `%s`", err, topic)
+	}
+
+	b.topics[topic] = struct{}{}
+
+	return nil
+}
+
+// receive decodes the given message received from the broker, if needed, and
+// delivers it to the subscribed handlers.
+func (b *brokerDispatcher) receive(ctx context.Context, topic string, message interface{}) error {
+	event := message
+
+	if raw, ok := message.([]byte); ok && b.opts.unmarshal != nil {
+		decoded, err := b.opts.unmarshal(raw)
+		if err != nil {
+			err = fmt.Errorf("%w: could not decode message received from topic `%s`", err, topic)
+			b.opts.observer.Dropped(observe.Labels{Component: componentDispatcher, Topic: topic}, err)
+
+			if b.opts.onError != nil {
+				b.opts.onError(err)
+			}
 
-	_, err = m.broker.Subscribe(ctx, m.topic, handler)
+			return err
+		}
+
+		event = decoded
+	}
 
-	return err
+	return b.router.deliver(ctx, topic, event)
 }
 
-func (m *memoryDispatcher) validateHandler(fn interface{}) (reflect.Type, error) {
+// wrap applies the configured middlewares chain to the given function.
+func (b *brokerDispatcher) wrap(info HandlerInfo, fn HandlerFunc) HandlerFunc {
+	for i := len(b.opts.middlewares) - 1; i >= 0; i-- {
+		fn = b.opts.middlewares[i](info, fn)
+	}
+
+	return fn
+}
+
+func (b *brokerDispatcher) validateHandler(fn interface{}) (reflect.Type, error) {
 	handlerType := reflect.TypeOf(fn)
 
 	if handlerType.Kind() != reflect.Func {
@@ -120,13 +358,17 @@ func (m *memoryDispatcher) validateHandler(fn interface{}) (reflect.Type, error)
 		return nil, fmt.Errorf("%w: must have an error as output parameter", ErrInvalidHandlerFunc)
 	}
 
-	if handlerType.In(1).Kind() == reflect.Ptr {
-		return nil, fmt.Errorf("%w: expected event cannot be a pointer", ErrInvalidHandlerFunc)
+	if err := validateEventType(handlerType.In(1)); err != nil {
+		return nil, err
 	}
 
-	if handlerType.In(1).Kind() == reflect.Interface {
-		return nil, fmt.Errorf("%w: expected event cannot be an interface", ErrInvalidHandlerFunc)
+	return handlerType, nil
+}
+
+func validateEventType(eventType reflect.Type) error {
+	if eventType.Kind() == reflect.Ptr {
+		return fmt.Errorf("%w: expected event cannot be a pointer", ErrInvalidHandlerFunc)
 	}
 
-	return handlerType, nil
+	return nil
 }
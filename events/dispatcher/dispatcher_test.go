@@ -8,8 +8,10 @@ import (
 	"github.com/Avalanche-io/counter"
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
 	"github.com/tangelo-labs/go-domain/events/dispatcher"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestMemoryDispatcher(t *testing.T) {
@@ -20,7 +22,7 @@ func TestMemoryDispatcher(t *testing.T) {
 		dpt := dispatcher.NewMemoryDispatcher()
 
 		t.Run("WHEN subscribing a function that expect a private message pointer THEN subscription fails", func(t *testing.T) {
-			sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg *privateMessage) error {
+			_, sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg *privateMessage) error {
 				return nil
 			})
 
@@ -28,27 +30,27 @@ func TestMemoryDispatcher(t *testing.T) {
 		})
 
 		t.Run("WHEN subscribing a function that expect a string pointer THEN subscription fails", func(t *testing.T) {
-			sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg *string) error {
+			_, sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg *string) error {
 				return nil
 			})
 
 			require.Error(t, sErr)
 		})
 
-		t.Run("WHEN subscribing a function that expect an proto.Message interface THEN subscription fails", func(t *testing.T) {
-			sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg proto.Message) error {
+		t.Run("WHEN subscribing a function that expect an proto.Message interface THEN subscription succeeds", func(t *testing.T) {
+			_, sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg proto.Message) error {
 				return nil
 			})
 
-			require.Error(t, sErr)
+			require.NoError(t, sErr)
 		})
 
-		t.Run("WHEN subscribing a function that expect an empty interface THEN subscription fails", func(t *testing.T) {
-			sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg interface{}) error {
+		t.Run("WHEN subscribing a function that expect an empty interface THEN subscription succeeds", func(t *testing.T) {
+			_, sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg interface{}) error {
 				return nil
 			})
 
-			require.Error(t, sErr)
+			require.NoError(t, sErr)
 		})
 	})
 
@@ -56,7 +58,7 @@ func TestMemoryDispatcher(t *testing.T) {
 		dpt := dispatcher.NewMemoryDispatcher()
 		rcv := counter.NewUnsigned()
 
-		sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg string) error {
+		_, sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg string) error {
 			rcv.Add(1)
 
 			return nil
@@ -74,7 +76,7 @@ func TestMemoryDispatcher(t *testing.T) {
 		dpt := dispatcher.NewMemoryDispatcher()
 		rcv := counter.NewUnsigned()
 
-		sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg privateMessage) error {
+		_, sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg privateMessage) error {
 			rcv.Add(1)
 
 			return nil
@@ -95,7 +97,7 @@ func TestMemoryDispatcher(t *testing.T) {
 		rcvPrivate := counter.NewUnsigned()
 		rcvString := counter.NewUnsigned()
 
-		sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg privateMessage) error {
+		_, sErr := dpt.Subscribe(ctx, func(ctx context.Context, msg privateMessage) error {
 			rcvPrivate.Add(1)
 
 			return nil
@@ -103,7 +105,7 @@ func TestMemoryDispatcher(t *testing.T) {
 
 		require.NoError(t, sErr)
 
-		sErr = dpt.Subscribe(ctx, func(ctx context.Context, msg string) error {
+		_, sErr = dpt.Subscribe(ctx, func(ctx context.Context, msg string) error {
 			rcvString.Add(1)
 
 			return nil
@@ -134,6 +136,118 @@ func TestMemoryDispatcher(t *testing.T) {
 	})
 }
 
+func TestMemoryDispatcherEnvelopes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("GIVEN a memory dispatcher with one payload subscriber and one envelope subscriber", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+
+		var (
+			payloads  []privateMessage
+			envelopes []events.Envelope
+			fromCtx   []events.Envelope
+		)
+
+		_, err := dpt.Subscribe(ctx, func(ctx context.Context, msg privateMessage) error {
+			payloads = append(payloads, msg)
+
+			if env, ok := events.EnvelopeFromContext(ctx); ok {
+				fromCtx = append(fromCtx, env)
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = dpt.Subscribe(ctx, func(ctx context.Context, env events.Envelope) error {
+			envelopes = append(envelopes, env)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN an envelope is dispatched THEN payload subscriber receives the payload AND envelope subscriber receives the envelope", func(t *testing.T) {
+			msg := privateMessage{Payload: gofakeit.LoremIpsumSentence(10)}
+			env := events.Wrap(ctx, msg)
+
+			require.NoError(t, dpt.Dispatch(ctx, env))
+
+			require.Equal(t, []privateMessage{msg}, payloads)
+			require.Equal(t, []events.Envelope{env}, envelopes)
+			require.Equal(t, []events.Envelope{env}, fromCtx)
+		})
+
+		t.Run("WHEN a plain event is dispatched THEN only payload subscriber receives it", func(t *testing.T) {
+			require.NoError(t, dpt.Dispatch(ctx, privateMessage{}))
+
+			require.Len(t, payloads, 2)
+			require.Len(t, envelopes, 1)
+		})
+	})
+}
+
 type privateMessage struct {
 	Payload string
 }
+
+func TestMemoryDispatcherInterfaceSubscriptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN a memory dispatcher with concrete, interface-typed and catch-all subscribers", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+
+		var calls []string
+
+		_, err := dpt.Subscribe(ctx, func(ctx context.Context, event events.Event) error {
+			calls = append(calls, "all")
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = dpt.Subscribe(ctx, func(ctx context.Context, msg privateMessage) error {
+			calls = append(calls, "private")
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, func(ctx context.Context, msg proto.Message) error {
+			calls = append(calls, "proto")
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN a private message is dispatched THEN the concrete and catch-all handlers are invoked in subscription order", func(t *testing.T) {
+			calls = nil
+
+			require.NoError(t, dpt.Dispatch(ctx, privateMessage{}))
+			require.Equal(t, []string{"all", "private"}, calls)
+		})
+
+		t.Run("WHEN a proto message is dispatched within an envelope THEN the interface-typed and catch-all handlers are invoked in subscription order", func(t *testing.T) {
+			calls = nil
+
+			require.NoError(t, dpt.Dispatch(ctx, events.Wrap(ctx, wrapperspb.String("test"))))
+			require.Equal(t, []string{"all", "proto"}, calls)
+		})
+
+		t.Run("WHEN dispatching repeatedly THEN handlers are always invoked in the same order", func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				calls = nil
+
+				require.NoError(t, dpt.Dispatch(ctx, privateMessage{}))
+				require.Equal(t, []string{"all", "private"}, calls)
+			}
+		})
+
+		t.Run("WHEN a nil event is dispatched THEN it fails AND no handler is invoked", func(t *testing.T) {
+			calls = nil
+
+			require.ErrorIs(t, dpt.Dispatch(ctx, nil), dispatcher.ErrNilEvent)
+			require.Empty(t, calls)
+		})
+	})
+}
@@ -0,0 +1,112 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+// ErrorPolicy defines how a dispatcher reacts when handlers fail.
+type ErrorPolicy int
+
+const (
+	// IgnoreErrors invokes every handler and never fails Dispatch calls.
+	// Handler failures are only reported to the callback given with
+	// WithErrorCallback, if any. This is the default policy.
+	IgnoreErrors ErrorPolicy = iota
+
+	// FailFast stops delivering an event as soon as one of its handlers
+	// fails, and returns that failure from Dispatch. Handlers subscribed
+	// after the failing one are not invoked.
+	FailFast
+
+	// CollectErrors invokes every handler, and returns from Dispatch the
+	// failures of all of them combined with errors.Join.
+	CollectErrors
+)
+
+// HandlerError describes the failure of a handler while handling an event.
+type HandlerError struct {
+	// Handler identifies the failing handler, by the name given with Named
+	// if any, or else by the name of its function, see HandlerInfo.Name. It
+	// matches the handler of dead letters, see DeadLetter.
+	Handler string
+
+	// Event is the event being handled.
+	Event events.Event
+
+	// Err is the error returned by the handler.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler `%s` failed on event `%s`: %s", e.Handler, events.TypeName(e.Event), e.Err)
+}
+
+// Unwrap returns the error returned by the handler.
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// WithErrorPolicy sets how the dispatcher reacts when handlers fail, see
+// ErrorPolicy.
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(o *options) {
+		o.errorPolicy = policy
+	}
+}
+
+// WithErrorCallback sets a function that is called with every handler
+// failure, as a *HandlerError, regardless of the error policy.
+func WithErrorCallback(fn func(err error)) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// deliveryResult carries the outcome of delivering an event through a broker,
+// as brokers do not report handler failures back to publishers. Brokers may
+// be shared by many dispatchers, so only the outcome of the publishing one is
+// recorded.
+type deliveryResult struct {
+	owner *brokerDispatcher
+	mu    sync.Mutex
+	err   error
+}
+
+// record joins the given delivery outcome of the given dispatcher into the
+// result, unless the result belongs to another dispatcher.
+func (r *deliveryResult) record(d *brokerDispatcher, err error) {
+	if r.owner != d || err == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = errors.Join(r.err, err)
+}
+
+// result returns the joined outcomes recorded so far.
+func (r *deliveryResult) result() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+type deliveryResultKey struct{}
+
+func contextWithDeliveryResult(ctx context.Context, res *deliveryResult) context.Context {
+	return context.WithValue(ctx, deliveryResultKey{}, res)
+}
+
+func deliveryResultFromContext(ctx context.Context) (*deliveryResult, bool) {
+	res, ok := ctx.Value(deliveryResultKey{}).(*deliveryResult)
+
+	return res, ok
+}
@@ -0,0 +1,159 @@
+package dispatcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestErrorPolicies(t *testing.T) {
+	ctx := context.Background()
+	errFirst := errors.New("first failed")
+	errSecond := errors.New("second failed")
+
+	// subscribe registers two failing handlers followed by a succeeding one,
+	// and returns the list of invoked handlers.
+	subscribe := func(t *testing.T, dpt dispatcher.Dispatcher) *[]string {
+		calls := make([]string, 0)
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg privateMessage) error {
+			calls = append(calls, "first")
+
+			return errFirst
+		})
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, func(ctx context.Context, msg privateMessage) error {
+			calls = append(calls, "second")
+
+			return errSecond
+		})
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, func(ctx context.Context, msg privateMessage) error {
+			calls = append(calls, "third")
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		return &calls
+	}
+
+	t.Run("GIVEN a dispatcher with the default policy and an error callback WHEN handlers fail THEN dispatch succeeds AND failures are reported", func(t *testing.T) {
+		var reported []error
+
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithErrorCallback(func(err error) {
+			reported = append(reported, err)
+		}))
+		calls := subscribe(t, dpt)
+
+		require.NoError(t, dpt.Dispatch(ctx, privateMessage{}))
+		require.Equal(t, []string{"first", "second", "third"}, *calls)
+		require.Len(t, reported, 2)
+		require.ErrorIs(t, reported[0], errFirst)
+		require.ErrorIs(t, reported[1], errSecond)
+	})
+
+	t.Run("GIVEN a fail-fast dispatcher WHEN a handler fails THEN dispatch returns its error AND later handlers are not invoked", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithErrorPolicy(dispatcher.FailFast))
+		calls := subscribe(t, dpt)
+
+		err := dpt.Dispatch(ctx, privateMessage{Payload: "x"})
+		require.ErrorIs(t, err, errFirst)
+		require.NotErrorIs(t, err, errSecond)
+		require.Equal(t, []string{"first"}, *calls)
+
+		var hErr *dispatcher.HandlerError
+
+		require.ErrorAs(t, err, &hErr)
+		require.Contains(t, hErr.Handler, "TestErrorPolicies")
+		require.Equal(t, privateMessage{Payload: "x"}, hErr.Event)
+		require.Contains(t, hErr.Error(), "privateMessage")
+	})
+
+	t.Run("GIVEN a fail-fast dispatcher WHEN a named handler fails THEN its error refers to it by that name", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithErrorPolicy(dispatcher.FailFast))
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg privateMessage) error {
+			return errFirst
+		}, dispatcher.Named("billing"))
+		require.NoError(t, err)
+
+		var hErr *dispatcher.HandlerError
+
+		require.ErrorAs(t, dpt.Dispatch(ctx, privateMessage{}), &hErr)
+		require.Equal(t, "billing", hErr.Handler)
+	})
+
+	t.Run("GIVEN a collecting dispatcher WHEN handlers fail THEN every handler is invoked AND dispatch returns all failures", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithErrorPolicy(dispatcher.CollectErrors))
+		calls := subscribe(t, dpt)
+
+		err := dpt.Dispatch(ctx, events.Wrap(ctx, privateMessage{}))
+		require.ErrorIs(t, err, errFirst)
+		require.ErrorIs(t, err, errSecond)
+		require.Equal(t, []string{"first", "second", "third"}, *calls)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	errFlaky := errors.New("flaky")
+	backoff := drain.ExponentialBackoffConfig{
+		Base:   time.Millisecond,
+		Factor: time.Millisecond,
+		Max:    5 * time.Millisecond,
+	}
+
+	t.Run("GIVEN a collecting dispatcher with a flaky handler subscribed with retries", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithErrorPolicy(dispatcher.CollectErrors))
+
+		var (
+			mu       sync.Mutex
+			attempts int
+		)
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			attempts++
+			if attempts < 3 {
+				return errFlaky
+			}
+
+			return nil
+		}, dispatcher.WithRetry(drain.NewExponentialBackoff[events.Event](backoff), 5))
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching an event THEN the handler is retried until it succeeds", func(t *testing.T) {
+			require.NoError(t, dpt.Dispatch(ctx, "test"))
+			require.Equal(t, 3, attempts)
+		})
+	})
+
+	t.Run("GIVEN a collecting dispatcher with a failing handler subscribed with limited retries", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithErrorPolicy(dispatcher.CollectErrors))
+		attempts := 0
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			attempts++
+
+			return errFlaky
+		}, dispatcher.WithRetry(drain.NewExponentialBackoff[events.Event](backoff), 3))
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching an event THEN attempts are exhausted AND the last failure is returned", func(t *testing.T) {
+			require.ErrorIs(t, dpt.Dispatch(ctx, "test"), errFlaky)
+			require.Equal(t, 3, attempts)
+		})
+	})
+}
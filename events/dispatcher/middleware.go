@@ -0,0 +1,173 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+// Middleware-related errors.
+var (
+	// ErrHandlerPanic is returned by the Recovery middleware when a handler,
+	// or the dispatch call itself, panics.
+	ErrHandlerPanic = errors.New("handler panicked")
+
+	// ErrHandlerTimeout is returned by the Timeout middleware when a handler
+	// does not finish within the configured duration.
+	ErrHandlerTimeout = errors.New("handler timed out")
+)
+
+// HandlerFunc is the untyped form of a handler invocation or a Dispatch call,
+// as seen by middlewares.
+type HandlerFunc func(ctx context.Context, event events.Event) error
+
+// HandlerInfo describes a subscribed handler.
+type HandlerInfo struct {
+	// Name identifies the handler function, as reported by the Go runtime.
+	Name string
+
+	// EventType is the type of the events the handler reacts to.
+	EventType reflect.Type
+}
+
+// IsDispatch whether this info describes a Dispatch call rather than a
+// subscribed handler. Middlewares wrapping a Dispatch call receive the zero
+// value of HandlerInfo.
+func (h HandlerInfo) IsDispatch() bool {
+	return h.EventType == nil
+}
+
+// Middleware intercepts Dispatch calls and handler invocations. It is applied
+// once per subscribed handler, and once for the Dispatch method itself. Use
+// HandlerInfo.IsDispatch to tell them apart.
+type Middleware func(info HandlerInfo, next HandlerFunc) HandlerFunc
+
+// Recovery builds a middleware that recovers from panics and converts them into
+// errors wrapping ErrHandlerPanic.
+func Recovery() Middleware {
+	return func(info HandlerInfo, next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, event events.Event) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%w: %s while handling event %T: %v", ErrHandlerPanic, info.name(), event, r)
+				}
+			}()
+
+			return next(ctx, event)
+		}
+	}
+}
+
+// Timeout builds a middleware that limits the time each handler invocation may
+// take. Handlers receive a context that is cancelled once the given duration
+// elapses, and if they do not return by then an error wrapping
+// ErrHandlerTimeout is returned on their behalf.
+//
+// Note that handlers ignoring their context keep running in background after
+// timing out, and panics raised by them after that moment are discarded.
+// Dispatch calls are not affected by this middleware.
+func Timeout(d time.Duration) Middleware {
+	return func(info HandlerInfo, next HandlerFunc) HandlerFunc {
+		if info.IsDispatch() {
+			return next
+		}
+
+		return func(ctx context.Context, event events.Event) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			done := make(chan timeoutResult, 1)
+
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- timeoutResult{panicked: true, panicValue: r}
+					}
+				}()
+
+				done <- timeoutResult{err: next(ctx, event)}
+			}()
+
+			select {
+			case res := <-done:
+				if res.panicked {
+					panic(res.panicValue)
+				}
+
+				return res.err
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %s did not handle event %T within %s", ErrHandlerTimeout, info.name(), event, d)
+			}
+		}
+	}
+}
+
+type timeoutResult struct {
+	err        error
+	panicked   bool
+	panicValue interface{}
+}
+
+// Logging builds a middleware that logs every Dispatch call and handler
+// invocation using the given logger. Failures are logged with error level,
+// successes with debug level.
+func Logging(logger *slog.Logger) Middleware {
+	return func(info HandlerInfo, next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, event events.Event) error {
+			start := time.Now()
+			err := next(ctx, event)
+			attrs := []slog.Attr{
+				slog.String("event", fmt.Sprintf("%T", event)),
+				slog.Duration("duration", time.Since(start)),
+			}
+
+			msg := "event dispatched"
+			if !info.IsDispatch() {
+				msg = "event handled"
+				attrs = append(attrs, slog.String("handler", info.Name))
+			}
+
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, msg, append(attrs, slog.Any("error", err))...)
+
+				return err
+			}
+
+			logger.LogAttrs(ctx, slog.LevelDebug, msg, attrs...)
+
+			return nil
+		}
+	}
+}
+
+// MeasureFunc receives the outcome and elapsed time of a Dispatch call or
+// handler invocation.
+type MeasureFunc func(info HandlerInfo, event events.Event, elapsed time.Duration, err error)
+
+// Measure builds a middleware that reports the duration of every Dispatch call
+// and handler invocation to the given function.
+func Measure(fn MeasureFunc) Middleware {
+	return func(info HandlerInfo, next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, event events.Event) error {
+			start := time.Now()
+			err := next(ctx, event)
+
+			fn(info, event, time.Since(start), err)
+
+			return err
+		}
+	}
+}
+
+func (h HandlerInfo) name() string {
+	if h.IsDispatch() {
+		return "dispatch"
+	}
+
+	return h.Name
+}
@@ -0,0 +1,190 @@
+package dispatcher_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+func TestMiddleware(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("GIVEN a dispatcher with measure and recovery middlewares AND a panicking subscriber", func(t *testing.T) {
+		m := &measurements{}
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithMiddleware(
+			dispatcher.Measure(m.record),
+			dispatcher.Recovery(),
+		))
+
+		_, err := dpt.Subscribe(ctx, func(ctx context.Context, msg string) error {
+			panic("boom")
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN an event is dispatched THEN process does not crash AND panic is reported as an error", func(t *testing.T) {
+			require.NotPanics(t, func() {
+				require.NoError(t, dpt.Dispatch(ctx, "test"))
+			})
+
+			handled := m.handlers()
+			require.Len(t, handled, 1)
+			require.ErrorIs(t, handled[0].err, dispatcher.ErrHandlerPanic)
+			require.Contains(t, handled[0].info.Name, "TestMiddleware")
+		})
+
+		t.Run("WHEN an event is dispatched THEN dispatch call is measured too", func(t *testing.T) {
+			require.NotEmpty(t, m.dispatches())
+		})
+	})
+
+	t.Run("GIVEN a dispatcher with a timeout middleware AND a slow subscriber", func(t *testing.T) {
+		m := &measurements{}
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithMiddleware(
+			dispatcher.Measure(m.record),
+			dispatcher.Timeout(50*time.Millisecond),
+		))
+
+		_, err := dpt.Subscribe(ctx, func(ctx context.Context, msg string) error {
+			<-ctx.Done()
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN an event is dispatched THEN handler invocation times out", func(t *testing.T) {
+			require.NoError(t, dpt.Dispatch(ctx, "test"))
+
+			handled := m.handlers()
+			require.Len(t, handled, 1)
+			require.ErrorIs(t, handled[0].err, dispatcher.ErrHandlerTimeout)
+			require.Less(t, handled[0].elapsed, time.Second)
+		})
+	})
+
+	t.Run("GIVEN a dispatcher with a logging middleware", func(t *testing.T) {
+		buf := &lockedBuffer{}
+		logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithMiddleware(dispatcher.Logging(logger)))
+
+		_, err := dpt.Subscribe(ctx, func(ctx context.Context, msg privateMessage) error {
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN an event is dispatched THEN both dispatch and handling are logged", func(t *testing.T) {
+			require.NoError(t, dpt.Dispatch(ctx, privateMessage{Payload: "hello"}))
+
+			out := buf.String()
+			require.Contains(t, out, "event dispatched")
+			require.Contains(t, out, "event handled")
+			require.Contains(t, out, "dispatcher_test.privateMessage")
+		})
+	})
+
+	t.Run("GIVEN a dispatcher with two tracing middlewares", func(t *testing.T) {
+		var (
+			trace []string
+			mu    sync.Mutex
+		)
+
+		tracer := func(name string) dispatcher.Middleware {
+			return func(info dispatcher.HandlerInfo, next dispatcher.HandlerFunc) dispatcher.HandlerFunc {
+				if info.IsDispatch() {
+					return next
+				}
+
+				return func(ctx context.Context, event events.Event) error {
+					mu.Lock()
+					trace = append(trace, name)
+					mu.Unlock()
+
+					return next(ctx, event)
+				}
+			}
+		}
+
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithMiddleware(tracer("a"), tracer("b")))
+
+		_, err := dpt.Subscribe(ctx, func(ctx context.Context, msg string) error {
+			mu.Lock()
+			trace = append(trace, "handler")
+			mu.Unlock()
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN an event is dispatched THEN middlewares are invoked in the given order", func(t *testing.T) {
+			require.NoError(t, dpt.Dispatch(ctx, "test"))
+			require.Equal(t, []string{"a", "b", "handler"}, trace)
+		})
+	})
+}
+
+type measurement struct {
+	info    dispatcher.HandlerInfo
+	elapsed time.Duration
+	err     error
+}
+
+type measurements struct {
+	items []measurement
+	mu    sync.Mutex
+}
+
+func (m *measurements) record(info dispatcher.HandlerInfo, _ events.Event, elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = append(m.items, measurement{info: info, elapsed: elapsed, err: err})
+}
+
+func (m *measurements) handlers() []measurement {
+	return m.filter(false)
+}
+
+func (m *measurements) dispatches() []measurement {
+	return m.filter(true)
+}
+
+func (m *measurements) filter(dispatch bool) []measurement {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]measurement, 0)
+
+	for _, item := range m.items {
+		if item.info.IsDispatch() == dispatch {
+			out = append(out, item)
+		}
+	}
+
+	return out
+}
+
+type lockedBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
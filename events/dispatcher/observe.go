@@ -0,0 +1,49 @@
+package dispatcher
+
+import (
+	"context"
+
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/observe"
+)
+
+// Components reported to observers, see observe.Labels.
+const (
+	componentDispatcher      = "dispatcher"
+	componentAsyncDispatcher = "async_dispatcher"
+)
+
+// WithObserver sets the observer notified about dispatched events, and about
+// their delivery to each handler, including retries. See observe.Metrics for
+// exporting such observations as metrics.
+func WithObserver(obs observe.Observer) Option {
+	return func(o *options) {
+		o.observer = obs
+	}
+}
+
+// WithAsyncObserver sets the observer notified about events being enqueued,
+// dropped due to backpressure, and handed over to the underlying dispatcher,
+// as well as about the number of events waiting in queues.
+func WithAsyncObserver(obs observe.Observer) AsyncOption {
+	return func(o *asyncOptions) {
+		o.observer = obs
+	}
+}
+
+// observed whether the given observer cares about observations. Labels are
+// only built for such observers, as building them allocates.
+func observed(obs observe.Observer) bool {
+	_, nop := obs.(observe.Nop)
+
+	return obs != nil && !nop
+}
+
+func handlerLabels(ctx context.Context, handler string, event events.Event) observe.Labels {
+	return observe.Labels{
+		Component: componentDispatcher,
+		Topic:     TopicFromContext(ctx),
+		Handler:   handler,
+		EventType: events.TypeName(event),
+	}
+}
@@ -0,0 +1,91 @@
+package dispatcher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+	"github.com/tangelo-labs/go-domain/events/drain"
+	"github.com/tangelo-labs/go-domain/events/observe"
+)
+
+func TestObserver(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN an observed dispatcher with a healthy handler AND a failing retried handler", func(t *testing.T) {
+		metrics := observe.NewMetrics()
+		dpt := dispatcher.NewMemoryDispatcher(dispatcher.WithObserver(metrics))
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			return nil
+		}, dispatcher.Named("healthy"), dispatcher.OnTopic("orders"))
+		require.NoError(t, err)
+
+		strategy := drain.NewExponentialBackoff[events.Event](drain.ExponentialBackoffConfig{
+			Base:   time.Millisecond,
+			Factor: time.Millisecond,
+			Max:    time.Millisecond,
+		})
+
+		_, err = dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			return errors.New("failed")
+		}, dispatcher.Named("failing"), dispatcher.OnTopic("orders"), dispatcher.WithRetry(strategy, 3))
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching an event THEN dispatch, deliveries, failures and retries are observed", func(t *testing.T) {
+			require.NoError(t, dpt.DispatchTo(ctx, "orders", "x"))
+
+			labels := func(handler string) observe.Labels {
+				return observe.Labels{Component: "dispatcher", Topic: "orders", Handler: handler, EventType: "string"}
+			}
+
+			require.EqualValues(t, 1, metrics.Value(observe.MetricDispatched, observe.Labels{Component: "dispatcher", Topic: "orders", EventType: "string"}))
+			require.EqualValues(t, 1, metrics.Value(observe.MetricDelivered, labels("healthy")))
+			require.EqualValues(t, 0, metrics.Value(observe.MetricFailed, labels("healthy")))
+			require.EqualValues(t, 1, metrics.Value(observe.MetricFailed, labels("failing")))
+			require.EqualValues(t, 2, metrics.Value(observe.MetricRetried, labels("failing")))
+		})
+	})
+
+	t.Run("GIVEN an observed asynchronous dispatcher dropping events when full", func(t *testing.T) {
+		metrics := observe.NewMetrics()
+		started := make(chan struct{}, 2)
+		release := make(chan struct{})
+		next := dispatcher.NewMemoryDispatcher()
+
+		_, err := dispatcher.On(next, func(ctx context.Context, msg string) error {
+			started <- struct{}{}
+			<-release
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		dpt := dispatcher.NewAsyncDispatcher(next,
+			dispatcher.WithQueueSize(1),
+			dispatcher.WithBackpressure(dispatcher.DropNewest),
+			dispatcher.WithAsyncObserver(metrics),
+		)
+
+		t.Run("WHEN dispatching more events than fit THEN drops and deliveries are observed", func(t *testing.T) {
+			labels := observe.Labels{Component: "async_dispatcher", Topic: dispatcher.DefaultTopic, EventType: "string"}
+
+			require.NoError(t, dpt.Dispatch(ctx, "a"))
+			<-started
+
+			require.NoError(t, dpt.Dispatch(ctx, "b"))
+			require.NoError(t, dpt.Dispatch(ctx, "c"))
+
+			close(release)
+			require.NoError(t, dpt.Shutdown(ctx))
+
+			require.EqualValues(t, 2, metrics.Value(observe.MetricDispatched, labels))
+			require.EqualValues(t, 1, metrics.Value(observe.MetricDropped, labels))
+			require.EqualValues(t, 2, metrics.Value(observe.MetricDelivered, labels))
+		})
+	})
+}
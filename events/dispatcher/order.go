@@ -0,0 +1,218 @@
+package dispatcher
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Ordering related errors.
+var (
+	// ErrDuplicateHandlerName is returned when subscribing a handler with a
+	// name already given to another subscribed handler.
+	ErrDuplicateHandlerName = errors.New("duplicate handler name")
+
+	// ErrOrderCycle is returned when the order constraints of a subscription
+	// contradict the ones of already subscribed handlers.
+	ErrOrderCycle = errors.New("handler order constraints form a cycle")
+)
+
+// ordering holds the constraints on the order a handler is invoked in,
+// relative to other handlers of the same event.
+type ordering struct {
+	name     string
+	priority int
+	before   []string
+	after    []string
+}
+
+// Named names the subscribed handler, so other subscriptions can refer to it
+// with Before and After. Names must be unique within a dispatcher.
+func Named(name string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.order.name = name
+	}
+}
+
+// WithPriority sets the priority of the subscribed handler. Handlers of the
+// same event are invoked from the highest priority to the lowest one, and in
+// registration order when priorities are equal. Defaults to zero.
+func WithPriority(priority int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.order.priority = priority
+	}
+}
+
+// Before makes the subscribed handler be invoked before the handlers named as
+// given, see Named, regardless of their priorities. Names not matching any
+// handler are ignored.
+func Before(names ...string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.order.before = append(o.order.before, names...)
+	}
+}
+
+// After makes the subscribed handler be invoked after the handlers named as
+// given, see Named, regardless of their priorities. Names not matching any
+// handler are ignored.
+func After(names ...string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.order.after = append(o.order.after, names...)
+	}
+}
+
+// orderRoutes sorts the given routes, which must be in registration order,
+// honoring their Before and After constraints first, then their priorities,
+// and then their registration order. An error is returned if constraints
+// form a cycle. Constraints are only followed through the given routes, so
+// these must be every route of a router, see routingTable.
+func orderRoutes(routes []*route) ([]*route, error) {
+	if !constrained(routes) {
+		out := append([]*route{}, routes...)
+
+		sort.SliceStable(out, func(i, j int) bool {
+			return out[i].order.priority > out[j].order.priority
+		})
+
+		return out, nil
+	}
+
+	edges := constraintEdges(routes)
+	pending := make([]int, len(routes))
+
+	for i := range edges {
+		for _, j := range edges[i] {
+			pending[j]++
+		}
+	}
+
+	out := make([]*route, 0, len(routes))
+	done := make([]bool, len(routes))
+
+	for len(out) < len(routes) {
+		next := -1
+
+		for i, rt := range routes {
+			if done[i] || pending[i] > 0 {
+				continue
+			}
+
+			if next < 0 || rt.order.priority > routes[next].order.priority {
+				next = i
+			}
+		}
+
+		if next < 0 {
+			return nil, ErrOrderCycle
+		}
+
+		done[next] = true
+		out = append(out, routes[next])
+
+		for _, j := range edges[next] {
+			pending[j]--
+		}
+	}
+
+	return out, nil
+}
+
+// constrained whether any of the given routes has Before or After constraints.
+func constrained(routes []*route) bool {
+	for _, rt := range routes {
+		if len(rt.order.before) > 0 || len(rt.order.after) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// constraintEdges returns, for each of the given routes, the indexes of the
+// routes that must be invoked after it. Constraints naming absent routes are
+// ignored.
+func constraintEdges(routes []*route) [][]int {
+	byName := make(map[string]int)
+
+	for i, rt := range routes {
+		if rt.order.name != "" {
+			byName[rt.order.name] = i
+		}
+	}
+
+	edges := make([][]int, len(routes))
+
+	for i, rt := range routes {
+		for _, name := range rt.order.before {
+			if j, ok := byName[name]; ok {
+				edges[i] = append(edges[i], j)
+			}
+		}
+
+		for _, name := range rt.order.after {
+			if j, ok := byName[name]; ok {
+				edges[j] = append(edges[j], i)
+			}
+		}
+	}
+
+	return edges
+}
+
+// validateOrder checks that the given route can be added to the given ones,
+// whose constraints are known to be acyclic. Hence, only cycles going through
+// the new route need to be looked for, and only when it takes part in any
+// constraint.
+func validateOrder(routes []*route, rt *route) error {
+	referenced := false
+
+	if rt.order.name != "" {
+		for _, other := range routes {
+			if other.order.name == rt.order.name {
+				return fmt.Errorf("%w: `%s`", ErrDuplicateHandlerName, rt.order.name)
+			}
+
+			if !referenced && (contains(other.order.before, rt.order.name) || contains(other.order.after, rt.order.name)) {
+				referenced = true
+			}
+		}
+	}
+
+	if !referenced && len(rt.order.before) == 0 && len(rt.order.after) == 0 {
+		return nil
+	}
+
+	all := append(routes[:len(routes):len(routes)], rt)
+	edges := constraintEdges(all)
+	start := len(all) - 1
+	visited := make([]bool, len(all))
+	stack := append([]int{}, edges[start]...)
+
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if i == start {
+			return fmt.Errorf("%w: subscribing handler `%s`", ErrOrderCycle, rt.info.Name)
+		}
+
+		if visited[i] {
+			continue
+		}
+
+		visited[i] = true
+		stack = append(stack, edges[i]...)
+	}
+
+	return nil
+}
+
+func contains(names []string, name string) bool {
+	for i := range names {
+		if names[i] == name {
+			return true
+		}
+	}
+
+	return false
+}
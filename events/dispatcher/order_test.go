@@ -0,0 +1,141 @@
+package dispatcher_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+func TestHandlerOrder(t *testing.T) {
+	ctx := context.Background()
+
+	record := func(calls *[]string, name string) func(context.Context, string) error {
+		return func(ctx context.Context, msg string) error {
+			*calls = append(*calls, name)
+
+			return nil
+		}
+	}
+
+	t.Run("GIVEN handlers subscribed with different priorities", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+
+		var calls []string
+
+		_, err := dispatcher.On(dpt, record(&calls, "notify"))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record(&calls, "audit"), dispatcher.WithPriority(10))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record(&calls, "metrics"), dispatcher.WithPriority(-1))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record(&calls, "email"))
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching THEN higher priorities run first AND equal priorities run in registration order", func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				calls = nil
+
+				require.NoError(t, dpt.Dispatch(ctx, "x"))
+				require.Equal(t, []string{"audit", "notify", "email", "metrics"}, calls)
+			}
+		})
+	})
+
+	t.Run("GIVEN handlers with named order constraints", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+
+		var calls []string
+
+		_, err := dispatcher.On(dpt, record(&calls, "notify"), dispatcher.Named("notify"), dispatcher.WithPriority(100))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record(&calls, "audit"), dispatcher.Named("audit"), dispatcher.Before("notify"))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record(&calls, "archive"), dispatcher.After("audit", "unknown"))
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching THEN constraints take precedence over priorities", func(t *testing.T) {
+			require.NoError(t, dpt.Dispatch(ctx, "x"))
+			require.Equal(t, []string{"audit", "notify", "archive"}, calls)
+		})
+
+		t.Run("WHEN subscribing a handler whose constraints form a cycle THEN it fails", func(t *testing.T) {
+			_, err := dispatcher.On(dpt, record(&calls, "cyclic"), dispatcher.Before("audit"), dispatcher.After("notify"))
+			require.ErrorIs(t, err, dispatcher.ErrOrderCycle)
+		})
+
+		t.Run("WHEN subscribing an unconstrained handler whose name closes a cycle THEN it fails", func(t *testing.T) {
+			_, err := dispatcher.On(dpt, record(&calls, "x"), dispatcher.Named("x"), dispatcher.Before("pending"))
+			require.NoError(t, err)
+
+			_, err = dispatcher.On(dpt, record(&calls, "y"), dispatcher.Named("y"), dispatcher.After("pending"), dispatcher.Before("x"))
+			require.NoError(t, err)
+
+			_, err = dispatcher.On(dpt, record(&calls, "pending"), dispatcher.Named("pending"))
+			require.ErrorIs(t, err, dispatcher.ErrOrderCycle)
+		})
+
+		t.Run("WHEN subscribing a handler with a taken name THEN it fails", func(t *testing.T) {
+			_, err := dispatcher.On(dpt, record(&calls, "other"), dispatcher.Named("audit"))
+			require.ErrorIs(t, err, dispatcher.ErrDuplicateHandlerName)
+		})
+
+		t.Run("WHEN the referenced handler unsubscribes THEN its name can be reused", func(t *testing.T) {
+			sub, err := dispatcher.On(dpt, record(&calls, "temp"), dispatcher.Named("temp"))
+			require.NoError(t, err)
+			require.NoError(t, sub.Unsubscribe())
+
+			_, err = dispatcher.On(dpt, record(&calls, "temp"), dispatcher.Named("temp"))
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("GIVEN handlers chained by constraints through a handler of another event", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+
+		var calls []string
+
+		_, err := dispatcher.On(dpt, record(&calls, "c"), dispatcher.Named("c"), dispatcher.WithPriority(100))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record(&calls, "a"), dispatcher.Named("a"), dispatcher.Before("b"))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, func(ctx context.Context, msg int) error {
+			calls = append(calls, "b")
+
+			return nil
+		}, dispatcher.Named("b"), dispatcher.Before("c"))
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching an event the linking handler does not expect THEN constraints are still followed transitively", func(t *testing.T) {
+			require.NoError(t, dpt.Dispatch(ctx, "x"))
+			require.Equal(t, []string{"a", "c"}, calls)
+		})
+	})
+}
+
+func BenchmarkSubscribe(b *testing.B) {
+	for _, n := range []int{100, 2000} {
+		b.Run(fmt.Sprintf("handlers=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dpt := dispatcher.NewMemoryDispatcher()
+
+				for j := 0; j < n; j++ {
+					if _, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+						return nil
+					}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package dispatcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+// WithRetry retries failed invocations of the subscribed handler, backing off
+// between attempts as dictated by the given strategy, such as
+// drain.NewExponentialBackoff or drain.NewBreakerStrategy. Strategies hold
+// state, so each subscription should be given its own instance.
+//
+// The handler is invoked up to maxAttempts times, zero meaning no limit. The
+// last failure is reported once attempts are exhausted, once the strategy
+// decides to drop the event, or once the dispatch context is done.
+func WithRetry(strategy drain.RetrySinkStrategy[events.Event], maxAttempts int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.retry = strategy
+		o.maxAttempts = maxAttempts
+	}
+}
+
+// retrying invokes the given function as dictated by the given strategy, and
+// calls onRetry before every new attempt.
+func retrying(
+	next HandlerFunc,
+	strategy drain.RetrySinkStrategy[events.Event],
+	maxAttempts int,
+	onRetry func(ctx context.Context, event events.Event, attempt int),
+) HandlerFunc {
+	return func(ctx context.Context, event events.Event) error {
+		for attempt := 1; ; attempt++ {
+			recordAttempt(ctx, attempt)
+
+			if attempt > 1 {
+				onRetry(ctx, event, attempt)
+			}
+
+			if backoff := strategy.Proceed(event); backoff > 0 {
+				timer := time.NewTimer(backoff)
+
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+
+			err := next(ctx, event)
+			if err == nil {
+				strategy.Success(event)
+
+				return nil
+			}
+
+			if strategy.Failure(event, err) || attempt == maxAttempts {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+	"github.com/tangelo-labs/go-domain/events/observe"
+)
+
+// route binds a handler to the type of the events it expects, and to the
+// topics it listens to.
+type route struct {
+	id     string
+	seq    uint64
+	topic  string
+	order  ordering
+	info   HandlerInfo
+	invoke HandlerFunc
+}
+
+// name identifies the handler of this route, by the name given with Named if
+// any, or else by the name of its function.
+func (r *route) name() string {
+	if r.order.name != "" {
+		return r.order.name
+	}
+
+	return r.info.Name
+}
+
+// accepts whether the handler of this route expects events of the given type.
+// Interface types match any event implementing them.
+func (r *route) accepts(eventType reflect.Type) bool {
+	if eventType == nil {
+		return false
+	}
+
+	if r.info.EventType.Kind() == reflect.Interface {
+		return eventType.Implements(r.info.EventType)
+	}
+
+	return eventType == r.info.EventType
+}
+
+// router delivers events to the registered handlers, ordered as dictated by
+// their order constraints and priorities, and then by registration order.
+//
+// Routes are kept in an immutable table which is replaced on every change
+// (copy-on-write), so delivering never blocks on subscriptions and handlers
+// may subscribe or unsubscribe while events are being delivered. Each table
+// indexes the routes matching every topic and event type seen so far, so
+// after the first delivery of a given type to a given topic, the cost of
+// routing does not depend on the number of handlers not interested in it.
+type router struct {
+	mu      sync.Mutex
+	table   atomic.Pointer[routingTable]
+	seq     uint64
+	policy  ErrorPolicy
+	onError func(error)
+
+	deadLetters drain.Sink[DeadLetter]
+	observer    observe.Observer
+	observed    bool
+}
+
+// routingTable is an immutable list of routes, along with an index of the
+// routes matching each topic and event type, lazily populated.
+//
+// Routes are ordered once for the whole table, rather than for each topic and
+// event type, as order constraints are transitive: a handler constrained to
+// run before another one must do so even when the handler that links them
+// does not listen to the same events.
+type routingTable struct {
+	routes      []*route
+	ordered     []*route
+	constrained bool
+	index       sync.Map
+}
+
+// newRoutingTable builds a table holding the given routes, which must be in
+// registration order.
+func newRoutingTable(routes []*route) *routingTable {
+	t := &routingTable{
+		routes:      routes,
+		ordered:     routes,
+		constrained: constrained(routes),
+	}
+
+	// constraints were validated when adding routes, so they cannot fail.
+	if ordered, err := orderRoutes(routes); err == nil {
+		t.ordered = ordered
+	}
+
+	return t
+}
+
+// with returns a table holding the routes of this table along with the given
+// one, which must be the most recently registered.
+func (t *routingTable) with(rt *route) *routingTable {
+	routes := make([]*route, 0, len(t.routes)+1)
+	routes = append(routes, t.routes...)
+	routes = append(routes, rt)
+
+	if t.constrained || constrained([]*route{rt}) {
+		return newRoutingTable(routes)
+	}
+
+	// without constraints, routes are just sorted by priority, so the new one
+	// goes after every route of the same or higher priority.
+	at := len(t.ordered)
+	for at > 0 && t.ordered[at-1].order.priority < rt.order.priority {
+		at--
+	}
+
+	ordered := make([]*route, 0, len(t.ordered)+1)
+	ordered = append(ordered, t.ordered[:at]...)
+	ordered = append(ordered, rt)
+	ordered = append(ordered, t.ordered[at:]...)
+
+	return &routingTable{routes: routes, ordered: ordered}
+}
+
+// routingKey identifies a topic and event type pair. Envelopes are indexed by
+// the type of their payload, flagged as envelopes.
+type routingKey struct {
+	topic     string
+	eventType reflect.Type
+	envelope  bool
+}
+
+func (r *router) add(topic string, order ordering, info HandlerInfo, invoke HandlerFunc) (*route, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rt := &route{
+		id:     strconv.FormatUint(r.seq+1, 10),
+		seq:    r.seq + 1,
+		topic:  topic,
+		order:  order,
+		info:   info,
+		invoke: invoke,
+	}
+
+	current := r.current()
+	if err := validateOrder(current.routes, rt); err != nil {
+		return nil, err
+	}
+
+	r.seq++
+	r.table.Store(current.with(rt))
+
+	return rt, nil
+}
+
+func (r *router) remove(rt *route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.current().routes
+	routes := make([]*route, 0, len(current))
+
+	for i := range current {
+		if current[i] != rt {
+			routes = append(routes, current[i])
+		}
+	}
+
+	r.table.Store(newRoutingTable(routes))
+}
+
+func (r *router) current() *routingTable {
+	if t := r.table.Load(); t != nil {
+		return t
+	}
+
+	return &routingTable{}
+}
+
+// lookup returns the routes matching the given key, in invocation order.
+func (t *routingTable) lookup(key routingKey) []*route {
+	if routes, ok := t.index.Load(key); ok {
+		return routes.([]*route)
+	}
+
+	var routes []*route
+
+	for _, rt := range t.ordered {
+		if !matchTopic(rt.topic, key.topic) {
+			continue
+		}
+
+		if rt.info.EventType == envelopeType {
+			if key.envelope {
+				routes = append(routes, rt)
+			}
+
+			continue
+		}
+
+		if rt.accepts(key.eventType) {
+			routes = append(routes, rt)
+		}
+	}
+
+	actual, _ := t.index.LoadOrStore(key, routes)
+
+	return actual.([]*route)
+}
+
+// deliver invokes every handler listening to the given topic that expects the
+// given event. When an envelope is given, handlers expecting events.Envelope
+// receive the envelope itself, and any other handler receives its payload.
+// Handler failures are handled according to the error policy of the router.
+func (r *router) deliver(ctx context.Context, topic string, event events.Event) error {
+	ctx, payload := deliveryContext(ctx, event)
+
+	_, isEnvelope := event.(events.Envelope)
+	routes := r.current().lookup(routingKey{
+		topic:     topic,
+		eventType: reflect.TypeOf(payload),
+		envelope:  isEnvelope,
+	})
+
+	var errs []error
+
+	for _, rt := range routes {
+		err := r.invoke(ctx, topic, rt, event, payload)
+		if err == nil {
+			continue
+		}
+
+		switch r.policy {
+		case FailFast:
+			return err
+		case CollectErrors:
+			errs = append(errs, err)
+		case IgnoreErrors:
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// invoke delivers the given event, or its payload, to the handler of the given
+// route. Failures are reported to the error callback and to the dead-letter
+// sink, if any, and returned as *HandlerError.
+func (r *router) invoke(ctx context.Context, topic string, rt *route, event, payload events.Event) error {
+	var attempts int
+
+	if r.deadLetters != nil {
+		ctx = contextWithAttempts(ctx, &attempts)
+	}
+
+	var err error
+
+	start := time.Now()
+
+	if rt.info.EventType == envelopeType {
+		err = rt.invoke(ctx, event)
+	} else {
+		err = rt.invoke(ctx, payload)
+	}
+
+	if err == nil {
+		if r.observed {
+			r.observer.Delivered(handlerLabels(ctx, rt.name(), event), time.Since(start))
+		}
+
+		return nil
+	}
+
+	if r.observed {
+		r.observer.Failed(handlerLabels(ctx, rt.name(), event), err)
+	}
+
+	hErr := &HandlerError{
+		Handler: rt.name(),
+		Event:   event,
+		Err:     err,
+	}
+
+	if r.onError != nil {
+		r.onError(hErr)
+	}
+
+	if r.deadLetters != nil {
+		r.deadLetter(DeadLetter{
+			Handler:  rt.name(),
+			Topic:    topic,
+			Event:    event,
+			Err:      err,
+			Attempts: max(attempts, 1),
+		})
+	}
+
+	return hErr
+}
+
+// deliveryContext prepares the context handlers are invoked with, and returns
+// the event such handlers expect unless they ask for envelopes.
+func deliveryContext(ctx context.Context, event events.Event) (context.Context, events.Event) {
+	ctx = context.WithValue(ctx, dispatchedEventKey{}, event)
+
+	if env, ok := event.(events.Envelope); ok {
+		return events.ContextWithEnvelope(ctx, env), env.Payload
+	}
+
+	return ctx, event
+}
+
+type dispatchedEventKey struct{}
+
+// dispatchedEvent returns the event given to Dispatch, as opposed to the one
+// received by handlers, which is the payload when dispatching envelopes.
+func dispatchedEvent(ctx context.Context) (events.Event, bool) {
+	event := ctx.Value(dispatchedEventKey{})
+
+	return event, event != nil
+}
@@ -0,0 +1,103 @@
+package dispatcher
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+// Subscription represents a handler subscribed to a dispatcher.
+type Subscription interface {
+	// ID uniquely identifies the subscription.
+	ID() string
+
+	// Unsubscribe removes the handler from the dispatcher, so it no longer
+	// receives events. Calling this method more than once has no effect.
+	Unsubscribe() error
+
+	// Done returns a channel that is closed once the subscription is removed.
+	Done() <-chan struct{}
+}
+
+// SubscribeOption configures a subscription.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	topic       string
+	order       ordering
+	lifetime    context.Context
+	retry       drain.RetrySinkStrategy[events.Event]
+	maxAttempts int
+}
+
+// UntilDone binds the subscription to the given context, so the handler is
+// automatically unsubscribed once the context is cancelled. Useful for
+// handlers registered by short-lived components such as request scopes or
+// websocket sessions.
+func UntilDone(ctx context.Context) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.lifetime = ctx
+	}
+}
+
+func newSubscribeOptions(opts ...SubscribeOption) subscribeOptions {
+	o := subscribeOptions{
+		topic: DefaultTopic,
+	}
+
+	for i := range opts {
+		opts[i](&o)
+	}
+
+	return o
+}
+
+type subscription struct {
+	id    string
+	unsub func() error
+	stop  func() bool
+	done  chan struct{}
+	once  sync.Once
+	err   error
+}
+
+// newSubscription builds a subscription that calls the given function when
+// unsubscribed, either explicitly or because its lifetime context is done.
+func newSubscription(id string, unsub func() error, opts subscribeOptions) *subscription {
+	s := &subscription{
+		id:    id,
+		unsub: unsub,
+		done:  make(chan struct{}),
+	}
+
+	if opts.lifetime != nil {
+		s.stop = context.AfterFunc(opts.lifetime, func() {
+			_ = s.Unsubscribe()
+		})
+	}
+
+	return s
+}
+
+func (s *subscription) ID() string {
+	return s.id
+}
+
+func (s *subscription) Unsubscribe() error {
+	s.once.Do(func() {
+		if s.stop != nil {
+			s.stop()
+		}
+
+		s.err = s.unsub()
+		close(s.done)
+	})
+
+	return s.err
+}
+
+func (s *subscription) Done() <-chan struct{} {
+	return s.done
+}
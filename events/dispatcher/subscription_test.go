@@ -0,0 +1,82 @@
+package dispatcher_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Avalanche-io/counter"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+func TestSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN a memory dispatcher with two subscribers for string messages", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+		first := counter.NewUnsigned()
+		second := counter.NewUnsigned()
+
+		sub, err := dpt.Subscribe(ctx, func(ctx context.Context, msg string) error {
+			first.Add(1)
+
+			return nil
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, sub.ID())
+
+		_, err = dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			second.Add(1)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN the first one unsubscribes THEN it no longer receives messages AND the second one does", func(t *testing.T) {
+			require.NoError(t, sub.Unsubscribe())
+			require.NoError(t, sub.Unsubscribe())
+
+			select {
+			case <-sub.Done():
+			default:
+				t.Fatal("subscription should be done")
+			}
+
+			require.NoError(t, dpt.Dispatch(ctx, "test"))
+			require.EqualValues(t, 0, first.Get())
+			require.EqualValues(t, 1, second.Get())
+		})
+	})
+
+	t.Run("GIVEN a memory dispatcher with a subscriber bound to a context", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+		rcv := counter.NewUnsigned()
+		scope, cancel := context.WithCancel(ctx)
+
+		sub, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			rcv.Add(1)
+
+			return nil
+		}, dispatcher.UntilDone(scope))
+		require.NoError(t, err)
+
+		t.Run("WHEN a message is dispatched before the context is cancelled THEN it is received", func(t *testing.T) {
+			require.NoError(t, dpt.Dispatch(ctx, "test"))
+			require.EqualValues(t, 1, rcv.Get())
+		})
+
+		t.Run("WHEN the context is cancelled THEN the subscriber detaches AND no longer receives messages", func(t *testing.T) {
+			cancel()
+
+			select {
+			case <-sub.Done():
+			case <-time.After(time.Second):
+				t.Fatal("subscription should have been detached")
+			}
+
+			require.NoError(t, dpt.Dispatch(ctx, "test"))
+			require.EqualValues(t, 1, rcv.Get())
+		})
+	})
+}
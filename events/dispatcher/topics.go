@@ -0,0 +1,113 @@
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+// DefaultTopic is the topic used by Dispatch, and by subscriptions not bound
+// to any topic.
+const DefaultTopic = "default"
+
+// wildcard matches any topic, or any segment of a topic when used as suffix.
+const wildcard = "*"
+
+// OnTopic binds a subscription to the given topic, so the handler only
+// receives events dispatched to that topic with DispatchTo. Besides exact
+// topic names, the following wildcard patterns are supported:
+//
+//   - "*" matches every topic.
+//   - "orders.*" matches every topic starting with "orders.", such as
+//     "orders.created" or "orders.lines.added".
+//
+// Note that brokers deliver events by topic, so wildcard subscriptions only
+// receive events from topics known to the dispatcher, either because they
+// were dispatched to or subscribed to through it.
+func OnTopic(pattern string) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.topic = pattern
+	}
+}
+
+// isWildcard whether the given topic pattern is a wildcard pattern.
+func isWildcard(pattern string) bool {
+	return pattern == wildcard || strings.HasSuffix(pattern, "."+wildcard)
+}
+
+// matchTopic whether the given topic matches the given pattern.
+func matchTopic(pattern, topic string) bool {
+	if pattern == wildcard {
+		return true
+	}
+
+	if isWildcard(pattern) {
+		return strings.HasPrefix(topic, strings.TrimSuffix(pattern, wildcard))
+	}
+
+	return pattern == topic
+}
+
+type topicKey struct{}
+
+func contextWithTopic(ctx context.Context, topic string) context.Context {
+	return context.WithValue(ctx, topicKey{}, topic)
+}
+
+// TopicFromContext returns the topic the event being dispatched or handled
+// was dispatched to. Useful for middlewares and wildcard subscribers.
+func TopicFromContext(ctx context.Context) string {
+	if topic, ok := ctx.Value(topicKey{}).(string); ok {
+		return topic
+	}
+
+	return DefaultTopic
+}
+
+type channel struct {
+	Dispatcher
+	topic string
+}
+
+// Channel returns a view of the given dispatcher bound to the given topic:
+// events dispatched through it are dispatched to that topic, and handlers
+// subscribed through it only receive events from that topic. Dispatching
+// through it to any other topic fails with ErrInvalidTopic. Use one channel
+// per bounded context to keep them isolated from each other while sharing a
+// single dispatcher.
+//
+// As events cannot be dispatched to wildcard topics, building a channel bound
+// to such a topic fails with ErrInvalidTopic.
+func Channel(d Dispatcher, topic string) (Dispatcher, error) {
+	if isWildcard(topic) {
+		return nil, fmt.Errorf("%w: cannot bind a channel to wildcard topic `%s`", ErrInvalidTopic, topic)
+	}
+
+	if c, ok := d.(*channel); ok {
+		d = c.Dispatcher
+	}
+
+	return &channel{Dispatcher: d, topic: topic}, nil
+}
+
+func (c *channel) Dispatch(ctx context.Context, event events.Event) error {
+	return c.Dispatcher.DispatchTo(ctx, c.topic, event)
+}
+
+func (c *channel) DispatchTo(ctx context.Context, topic string, event events.Event) error {
+	if topic != c.topic {
+		return fmt.Errorf("%w: channel `%s` cannot dispatch to topic `%s`", ErrInvalidTopic, c.topic, topic)
+	}
+
+	return c.Dispatcher.DispatchTo(ctx, topic, event)
+}
+
+func (c *channel) unwrap() Dispatcher {
+	return c.Dispatcher
+}
+
+func (c *channel) Subscribe(ctx context.Context, handlerFn interface{}, opts ...SubscribeOption) (Subscription, error) {
+	return c.Dispatcher.Subscribe(ctx, handlerFn, append(opts, OnTopic(c.topic))...)
+}
@@ -0,0 +1,116 @@
+package dispatcher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+func TestTopics(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN a dispatcher with topic-scoped, prefix wildcard and catch-all wildcard subscribers", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+
+		var calls []string
+
+		record := func(name string) func(context.Context, string) error {
+			return func(ctx context.Context, msg string) error {
+				calls = append(calls, name+":"+dispatcher.TopicFromContext(ctx))
+
+				return nil
+			}
+		}
+
+		_, err := dispatcher.On(dpt, record("all"), dispatcher.OnTopic("*"))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record("created"), dispatcher.OnTopic("orders.created"))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record("orders"), dispatcher.OnTopic("orders.*"))
+		require.NoError(t, err)
+
+		_, err = dispatcher.On(dpt, record("default"))
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching to a topic THEN only matching subscribers receive it in subscription order", func(t *testing.T) {
+			calls = nil
+
+			require.NoError(t, dpt.DispatchTo(ctx, "orders.created", "x"))
+			require.Equal(t, []string{"all:orders.created", "created:orders.created", "orders:orders.created"}, calls)
+		})
+
+		t.Run("WHEN dispatching to another topic of the same prefix THEN topic-scoped subscribers of other topics do not receive it", func(t *testing.T) {
+			calls = nil
+
+			require.NoError(t, dpt.DispatchTo(ctx, "orders.cancelled", "x"))
+			require.Equal(t, []string{"all:orders.cancelled", "orders:orders.cancelled"}, calls)
+		})
+
+		t.Run("WHEN dispatching without topic THEN default subscribers and catch-all wildcard subscribers receive it", func(t *testing.T) {
+			calls = nil
+
+			require.NoError(t, dpt.Dispatch(ctx, "x"))
+			require.Equal(t, []string{"all:default", "default:default"}, calls)
+		})
+
+		t.Run("WHEN dispatching to a wildcard topic THEN it fails", func(t *testing.T) {
+			require.ErrorIs(t, dpt.DispatchTo(ctx, "orders.*", "x"), dispatcher.ErrInvalidTopic)
+		})
+	})
+
+	t.Run("GIVEN two bounded context channels sharing a dispatcher", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+		orders, err := dispatcher.Channel(dpt, "orders")
+		require.NoError(t, err)
+
+		billing, err := dispatcher.Channel(dpt, "billing")
+		require.NoError(t, err)
+
+		var ordersCalls, billingCalls []string
+
+		_, err = dispatcher.On(orders, func(ctx context.Context, msg string) error {
+			ordersCalls = append(ordersCalls, msg)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = billing.Subscribe(ctx, func(ctx context.Context, msg string) error {
+			billingCalls = append(billingCalls, msg)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN dispatching through each channel THEN events stay isolated within their channel", func(t *testing.T) {
+			require.NoError(t, orders.Dispatch(ctx, "order"))
+			require.NoError(t, billing.Dispatch(ctx, "invoice"))
+			require.NoError(t, dpt.Dispatch(ctx, "other"))
+
+			require.Equal(t, []string{"order"}, ordersCalls)
+			require.Equal(t, []string{"invoice"}, billingCalls)
+		})
+
+		t.Run("WHEN dispatching through a channel to another topic THEN it fails AND nothing leaks", func(t *testing.T) {
+			require.ErrorIs(t, orders.DispatchTo(ctx, "billing", "leak"), dispatcher.ErrInvalidTopic)
+			require.NoError(t, orders.DispatchTo(ctx, "orders", "order2"))
+
+			require.Equal(t, []string{"order", "order2"}, ordersCalls)
+			require.Equal(t, []string{"invoice"}, billingCalls)
+		})
+	})
+
+	t.Run("GIVEN a dispatcher WHEN binding a channel to a wildcard topic THEN it fails", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+
+		_, err := dispatcher.Channel(dpt, "orders.*")
+		require.ErrorIs(t, err, dispatcher.ErrInvalidTopic)
+
+		_, err = dispatcher.Channel(dpt, "*")
+		require.ErrorIs(t, err, dispatcher.ErrInvalidTopic)
+	})
+}
@@ -0,0 +1,51 @@
+package dispatcher
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+// On registers a handler function to react on events of type T. It is the
+// type-safe counterpart of Dispatcher.Subscribe: the signature of the handler
+// is checked at compile time, and when used with dispatchers built by this
+// package the handler is invoked directly, without reflection. For other
+// Dispatcher implementations it falls back to Dispatcher.Subscribe.
+//
+// As with Dispatcher.Subscribe, T cannot be a pointer, and when T is an
+// interface type the handler receives every event implementing it.
+//
+// Example:
+//
+//	sub, err := dispatcher.On(d, func(ctx context.Context, e OrderCreated) error {
+//		return nil
+//	})
+func On[T any](d Dispatcher, fn func(ctx context.Context, event T) error, opts ...SubscribeOption) (Subscription, error) {
+	ctx := context.Background()
+
+	if c, ok := d.(*channel); ok {
+		d = c.Dispatcher
+		opts = append(opts, OnTopic(c.topic))
+	}
+
+	b, ok := d.(*brokerDispatcher)
+	if !ok {
+		return d.Subscribe(ctx, fn, opts...)
+	}
+
+	eventType := reflect.TypeOf((*T)(nil)).Elem()
+	if err := validateEventType(eventType); err != nil {
+		return nil, err
+	}
+
+	info := HandlerInfo{
+		Name:      runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name(),
+		EventType: eventType,
+	}
+
+	return b.subscribe(ctx, info, newSubscribeOptions(opts...), func(ctx context.Context, event events.Event) error {
+		return fn(ctx, event.(T))
+	})
+}
@@ -0,0 +1,150 @@
+package dispatcher_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Avalanche-io/counter"
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+func TestOn(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GIVEN a memory dispatcher with a typed subscriber for privateMessage", func(t *testing.T) {
+		dpt := dispatcher.NewMemoryDispatcher()
+
+		var received []privateMessage
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg privateMessage) error {
+			received = append(received, msg)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN a private message and a string are dispatched THEN only the private message is received", func(t *testing.T) {
+			msg := privateMessage{Payload: gofakeit.LoremIpsumSentence(10)}
+
+			require.NoError(t, dpt.Dispatch(ctx, msg))
+			require.NoError(t, dpt.Dispatch(ctx, "test"))
+			require.Equal(t, []privateMessage{msg}, received)
+		})
+
+		t.Run("WHEN an envelope is dispatched THEN its payload is received", func(t *testing.T) {
+			msg := privateMessage{Payload: gofakeit.LoremIpsumSentence(10)}
+
+			require.NoError(t, dpt.Dispatch(ctx, events.Wrap(ctx, msg)))
+			require.Equal(t, msg, received[len(received)-1])
+		})
+	})
+
+	t.Run("GIVEN a memory dispatcher WHEN subscribing a typed handler for a pointer type THEN subscription fails", func(t *testing.T) {
+		_, err := dispatcher.On(dispatcher.NewMemoryDispatcher(), func(ctx context.Context, msg *privateMessage) error {
+			return nil
+		})
+
+		require.ErrorIs(t, err, dispatcher.ErrInvalidHandlerFunc)
+	})
+
+	t.Run("GIVEN a custom dispatcher implementation WHEN subscribing a typed handler THEN it falls back to Subscribe", func(t *testing.T) {
+		dpt := customDispatcher{Dispatcher: dispatcher.NewMemoryDispatcher()}
+		rcv := counter.NewUnsigned()
+
+		_, err := dispatcher.On(dpt, func(ctx context.Context, msg string) error {
+			rcv.Add(1)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, dpt.Dispatch(ctx, "test"))
+		require.EqualValues(t, 1, rcv.Get())
+	})
+}
+
+func BenchmarkDispatch(b *testing.B) {
+	ctx := context.Background()
+	msg := privateMessage{Payload: "benchmark"}
+	handler := func(ctx context.Context, msg privateMessage) error {
+		return nil
+	}
+
+	b.Run("reflection", func(b *testing.B) {
+		dpt := dispatcher.NewMemoryDispatcher()
+		_, err := dpt.Subscribe(ctx, handler)
+		require.NoError(b, err)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			_ = dpt.Dispatch(ctx, msg)
+		}
+	})
+
+	b.Run("typed", func(b *testing.B) {
+		dpt := dispatcher.NewMemoryDispatcher()
+		_, err := dispatcher.On(dpt, handler)
+		require.NoError(b, err)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			_ = dpt.Dispatch(ctx, msg)
+		}
+	})
+}
+
+type customDispatcher struct {
+	dispatcher.Dispatcher
+}
+
+func BenchmarkRouting(b *testing.B) {
+	ctx := context.Background()
+
+	// eventType returns a distinct event type for each given index.
+	eventType := func(i int) reflect.Type {
+		return reflect.ArrayOf(i+1, reflect.TypeOf(byte(0)))
+	}
+
+	for _, handlers := range []int{10, 100, 1000} {
+		for _, types := range []int{1, 10, 100} {
+			b.Run(fmt.Sprintf("handlers=%d/types=%d", handlers, types), func(b *testing.B) {
+				dpt := dispatcher.NewMemoryDispatcher()
+
+				// handlers are spread across the given number of event types,
+				// and only those of the first type match the dispatched event.
+				for i := 0; i < handlers; i++ {
+					fnType := reflect.FuncOf(
+						[]reflect.Type{reflect.TypeOf((*context.Context)(nil)).Elem(), eventType(i % types)},
+						[]reflect.Type{reflect.TypeOf((*error)(nil)).Elem()},
+						false,
+					)
+
+					fn := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+						return []reflect.Value{reflect.Zero(fnType.Out(0))}
+					})
+
+					_, err := dpt.Subscribe(ctx, fn.Interface())
+					require.NoError(b, err)
+				}
+
+				event := reflect.New(eventType(0)).Elem().Interface()
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					_ = dpt.Dispatch(ctx, event)
+				}
+			})
+		}
+	}
+}
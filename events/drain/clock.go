@@ -0,0 +1,39 @@
+package drain
+
+import "time"
+
+// Clock provides time to the sinks of this package that depend on it, so it
+// can be controlled in tests. See WithClock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AfterFunc calls the given function in its own goroutine once the given
+	// duration elapses, unless the returned timer is stopped before.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a timer created by a Clock.
+type Timer interface {
+	// Stop prevents the timer from firing, reporting whether it was stopped
+	// before firing.
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// WithClock sets the clock used by time dependent sinks, such as the ones
+// built with NewBatcher. Defaults to the system clock.
+func WithClock(clock Clock) SinkOption {
+	return func(o *sinkOptions) {
+		o.clock = clock
+	}
+}
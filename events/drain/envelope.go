@@ -0,0 +1,68 @@
+package drain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+// UnwrapPayload is a MapperFn that replaces envelopes by their payload, so the
+// underlying sink only sees the original domain events. Other messages are
+// passed through untouched.
+//
+// Usage:
+//
+//	drain.NewMapper[events.Event](dst, drain.UnwrapPayload)
+func UnwrapPayload(m events.Event) (events.Event, error) {
+	return events.Unwrap(m), nil
+}
+
+// WrapEnvelope is a MapperFn that wraps messages not being envelopes yet into a
+// new envelope, so the underlying sink always sees envelopes.
+//
+// Usage:
+//
+//	drain.NewMapper[events.Event](dst, drain.WrapEnvelope)
+func WrapEnvelope(m events.Event) (events.Event, error) {
+	return events.Wrap(context.Background(), m), nil
+}
+
+type envelopeSink struct {
+	*baseSink
+	dst Sink[events.Envelope]
+}
+
+// NewEnvelopeSink adapts a sink of envelopes so it can receive any event.
+// Messages not being envelopes are wrapped into a new envelope before being
+// written to the underlying sink.
+func NewEnvelopeSink(dst Sink[events.Envelope]) Sink[events.Event] {
+	return &envelopeSink{
+		baseSink: newCloseTrait(),
+		dst:      dst,
+	}
+}
+
+func (e *envelopeSink) Write(message events.Event) error {
+	if e.baseSink.IsClosed() {
+		return fmt.Errorf("%w: envelope sink could not write message %T", ErrSinkClosed, message)
+	}
+
+	if errD := e.dst.Write(events.Wrap(context.Background(), message)); errD != nil {
+		return fmt.Errorf("%w: envelope sink could not write message %T in underlying sink", errD, message)
+	}
+
+	return nil
+}
+
+func (e *envelopeSink) Close() error {
+	if errD := e.dst.Close(); errD != nil {
+		return fmt.Errorf("%w: envelope sink could not close underlying sink", errD)
+	}
+
+	if errB := e.baseSink.Close(); errB != nil {
+		return fmt.Errorf("%w: envelope sink could not close", errB)
+	}
+
+	return nil
+}
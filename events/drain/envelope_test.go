@@ -0,0 +1,43 @@
+package drain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestEnvelopeSinks(t *testing.T) {
+	t.Run("GIVEN a mapper sink unwrapping payloads WHEN writing envelopes and plain events THEN only payloads reach the sink", func(t *testing.T) {
+		ts := newTestSink[events.Event](t, 2)
+		sink := drain.NewMapper[events.Event](ts, drain.UnwrapPayload)
+
+		require.NoError(t, sink.Write(events.Wrap(context.Background(), fakeMessage{ID: "a"})))
+		require.NoError(t, sink.Write(fakeMessage{ID: "b"}))
+		require.Equal(t, []events.Event{fakeMessage{ID: "a"}, fakeMessage{ID: "b"}}, ts.messages)
+
+		checkClose(t, sink)
+	})
+
+	t.Run("GIVEN an envelope sink WHEN writing envelopes and plain events THEN only envelopes reach the sink", func(t *testing.T) {
+		ts := newTestSink[events.Envelope](t, 2)
+		sink := drain.NewEnvelopeSink(ts)
+		env := events.Wrap(context.Background(), fakeMessage{ID: "a"})
+
+		require.NoError(t, sink.Write(env))
+		require.NoError(t, sink.Write(fakeMessage{ID: "b"}))
+
+		require.Equal(t, env, ts.messages[0])
+		require.Equal(t, fakeMessage{ID: "b"}, ts.messages[1].Payload)
+		require.NotEmpty(t, ts.messages[1].EventID)
+
+		checkClose(t, sink)
+	})
+
+	t.Run("GIVEN an envelope wrapping a non-proto payload WHEN marshalling it as proto THEN payload is unwrapped before failing", func(t *testing.T) {
+		_, err := drain.ProtoMarshaller(events.Wrap(context.Background(), fakeMessage{ID: "a"}))
+		require.ErrorContains(t, err, "drain_test.fakeMessage")
+	})
+}
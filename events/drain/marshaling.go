@@ -13,17 +13,24 @@ import (
 // Marshaller converts an input message into a byte stream.
 type Marshaller[M any] func(M) ([]byte, error)
 
+// Unmarshaller converts a byte stream back into a message.
+type Unmarshaller[M any] func([]byte) (M, error)
+
 // List of commonly used marshallers.
 var (
 	// JSONMarshaller a simple JSON marshaller function that uses the standard
 	// library `encoding/json` package to marshal events.Event messages.
+	// Envelopes are marshalled as a whole, metadata included.
 	JSONMarshaller = func(m events.Event) ([]byte, error) {
 		return json.Marshal(m)
 	}
 
 	// ProtoMarshaller assumes the input message as `proto.Message`, and marshall
-	// using `proto.Marshal()`.
+	// using `proto.Marshal()`. Envelopes are unwrapped, so only their payload
+	// is marshalled.
 	ProtoMarshaller = func(m events.Event) ([]byte, error) {
+		m = events.Unwrap(m)
+
 		if pb, ok := m.(proto.Message); ok {
 			return proto.Marshal(pb)
 		}
@@ -34,7 +41,10 @@ var (
 	// AnyPBMarshaller assumes the input message as a `proto.Message`, and
 	// marshall using `anypb` package. That is, it wraps the original proto
 	// message in an `Any` message, and then marshall the `Any` message.
+	// Envelopes are unwrapped, so only their payload is marshalled.
 	AnyPBMarshaller = func(m events.Event) ([]byte, error) {
+		m = events.Unwrap(m)
+
 		if pb, ok := m.(proto.Message); ok {
 			anyMsg, err := anypb.New(pb)
 			if err != nil {
@@ -49,7 +59,10 @@ var (
 
 	// ProtoJSONMarshaller similar to "AnyPBMarshaller". Assumes that the message
 	// is a `proto.Message` instance, and marshall it using `protojson` package.
+	// Envelopes are unwrapped, so only their payload is marshalled.
 	ProtoJSONMarshaller = func(m events.Event) ([]byte, error) {
+		m = events.Unwrap(m)
+
 		if pb, ok := m.(proto.Message); ok {
 			return protojson.Marshal(pb)
 		}
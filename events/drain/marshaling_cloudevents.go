@@ -0,0 +1,306 @@
+package drain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+// CloudEvents related constants.
+const (
+	// CloudEventsSpecVersion is the version of the CloudEvents specification
+	// implemented by this package.
+	CloudEventsSpecVersion = "1.0"
+
+	// CloudEventsContentType is the content type of structured-mode JSON
+	// CloudEvents.
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// cloudEventsDataContentType is the content type of the data attribute.
+	cloudEventsDataContentType = "application/json"
+
+	// cloudEventsHeaderPrefix is the prefix of binary-mode headers.
+	cloudEventsHeaderPrefix = "ce-"
+)
+
+// ErrNotEnvelope is returned when encoding as CloudEvents messages that are
+// not envelopes, which hold no stable identity.
+var ErrNotEnvelope = errors.New("message is not an envelope")
+
+// cloudEvent is the structured-mode JSON representation of a CloudEvent, see
+// https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/formats/json-format.md
+type cloudEvent struct {
+	SpecVersion      string          `json:"specversion"`
+	ID               string          `json:"id"`
+	Source           string          `json:"source"`
+	Type             string          `json:"type"`
+	Subject          string          `json:"subject,omitempty"`
+	Time             *time.Time      `json:"time,omitempty"`
+	DataContentType  string          `json:"datacontenttype,omitempty"`
+	CorrelationID    string          `json:"correlationid,omitempty"`
+	CausationID      string          `json:"causationid,omitempty"`
+	AggregateType    string          `json:"aggregatetype,omitempty"`
+	AggregateVersion string          `json:"aggregateversion,omitempty"`
+	Metadata         string          `json:"metadata,omitempty"`
+	Data             json.RawMessage `json:"data"`
+}
+
+// NewCloudEventsMarshaller builds a marshaller that encodes messages as
+// CloudEvents 1.0 structured-mode JSON documents. CloudEvents attributes are
+// populated as follows:
+//
+// - `id`: envelope event ID.
+// - `type`: name under which the event type is registered in the registry.
+// - `source`: the given source, e.g. "/services/orders".
+// - `subject`: envelope aggregate ID.
+// - `time`: envelope occurrence time.
+// - `data`: JSON representation of the event payload.
+//
+// Envelope correlation, causation, aggregate type and aggregate version are
+// carried as the `correlationid`, `causationid`, `aggregatetype` and
+// `aggregateversion` extension attributes. Envelope metadata is carried as the
+// `metadata` extension attribute, URL query encoded, e.g. "tenant=acme&x=1",
+// as metadata keys are not valid attribute names in general.
+//
+// Only envelopes can be marshalled, other messages fail with ErrNotEnvelope.
+// This keeps the `id` and `time` of an event stable across retries, so
+// consumers can deduplicate by `id`. Wrap events with events.Wrap, or record
+// them with events.Recorder, before handing them to sinks.
+func NewCloudEventsMarshaller(registry *events.Registry, source string) Marshaller[events.Event] {
+	return func(m events.Event) ([]byte, error) {
+		ce, err := newCloudEvent(registry, source, m)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(ce)
+	}
+}
+
+// NewCloudEventsUnmarshaller builds the inverse of NewCloudEventsMarshaller.
+// Decoded messages are always returned as events.Envelope, whose payload is
+// upcasted and decoded using the given registry.
+func NewCloudEventsUnmarshaller(registry *events.Registry) Unmarshaller[events.Event] {
+	return func(b []byte) (events.Event, error) {
+		var ce cloudEvent
+
+		if err := json.Unmarshal(b, &ce); err != nil {
+			return nil, fmt.Errorf("%w: could not unmarshal (cloudevents) message", err)
+		}
+
+		return ce.envelope(registry)
+	}
+}
+
+// CloudEventsBinaryEncoder encodes a message as a CloudEvent in binary mode,
+// that is, attributes are returned as transport headers and the data is
+// returned as the message body.
+type CloudEventsBinaryEncoder func(m events.Event) (headers map[string]string, body []byte, err error)
+
+// CloudEventsBinaryDecoder decodes a binary-mode CloudEvent back into a
+// message.
+type CloudEventsBinaryDecoder func(headers map[string]string, body []byte) (events.Event, error)
+
+// NewCloudEventsBinaryEncoder builds an encoder for transports supporting
+// message attributes, such as HTTP headers or Kafka headers. Attributes are
+// populated as described in NewCloudEventsMarshaller, and returned as headers
+// prefixed by "ce-", e.g. "ce-id" or "ce-type". The "content-type" header
+// describes the body. As with NewCloudEventsMarshaller, only envelopes can be
+// encoded.
+func NewCloudEventsBinaryEncoder(registry *events.Registry, source string) CloudEventsBinaryEncoder {
+	return func(m events.Event) (map[string]string, []byte, error) {
+		ce, err := newCloudEvent(registry, source, m)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		headers := map[string]string{
+			cloudEventsHeaderPrefix + "specversion": ce.SpecVersion,
+			cloudEventsHeaderPrefix + "id":          ce.ID,
+			cloudEventsHeaderPrefix + "source":      ce.Source,
+			cloudEventsHeaderPrefix + "type":        ce.Type,
+			"content-type":                          ce.DataContentType,
+		}
+
+		optional := map[string]string{
+			"subject":          ce.Subject,
+			"correlationid":    ce.CorrelationID,
+			"causationid":      ce.CausationID,
+			"aggregatetype":    ce.AggregateType,
+			"aggregateversion": ce.AggregateVersion,
+			"metadata":         ce.Metadata,
+		}
+
+		if ce.Time != nil {
+			optional["time"] = ce.Time.Format(time.RFC3339Nano)
+		}
+
+		for k, v := range optional {
+			if v != "" {
+				headers[cloudEventsHeaderPrefix+k] = v
+			}
+		}
+
+		return headers, ce.Data, nil
+	}
+}
+
+// NewCloudEventsBinaryDecoder builds the inverse of NewCloudEventsBinaryEncoder.
+// Header names are matched case-insensitively.
+func NewCloudEventsBinaryDecoder(registry *events.Registry) CloudEventsBinaryDecoder {
+	return func(headers map[string]string, body []byte) (events.Event, error) {
+		attrs := make(map[string]string, len(headers))
+		for k, v := range headers {
+			attrs[strings.ToLower(k)] = v
+		}
+
+		attr := func(name string) string {
+			return attrs[cloudEventsHeaderPrefix+name]
+		}
+
+		ce := cloudEvent{
+			SpecVersion:      attr("specversion"),
+			ID:               attr("id"),
+			Source:           attr("source"),
+			Type:             attr("type"),
+			Subject:          attr("subject"),
+			DataContentType:  attrs["content-type"],
+			CorrelationID:    attr("correlationid"),
+			CausationID:      attr("causationid"),
+			AggregateType:    attr("aggregatetype"),
+			AggregateVersion: attr("aggregateversion"),
+			Metadata:         attr("metadata"),
+			Data:             body,
+		}
+
+		if raw := attr("time"); raw != "" {
+			t, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: could not decode (cloudevents) time attribute", err)
+			}
+
+			ce.Time = &t
+		}
+
+		return ce.envelope(registry)
+	}
+}
+
+func newCloudEvent(registry *events.Registry, source string, m events.Event) (cloudEvent, error) {
+	env, ok := m.(events.Envelope)
+	if !ok {
+		return cloudEvent{}, fmt.Errorf("%w: could not marshal (cloudevents) message of type `%T`", ErrNotEnvelope, m)
+	}
+
+	if env.EventID == "" {
+		return cloudEvent{}, fmt.Errorf("could not marshal (cloudevents) message of type `%T`, missing event ID", env.Payload)
+	}
+
+	name, err := registry.NameOf(env)
+	if err != nil {
+		return cloudEvent{}, fmt.Errorf("%w: could not marshal (cloudevents) message", err)
+	}
+
+	data, err := json.Marshal(env.Payload)
+	if err != nil {
+		return cloudEvent{}, fmt.Errorf("%w: could not marshal (cloudevents) message data of type `%s`", err, name)
+	}
+
+	ce := cloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              env.EventID,
+		Source:          source,
+		Type:            name,
+		Subject:         env.AggregateID,
+		DataContentType: cloudEventsDataContentType,
+		CorrelationID:   env.CorrelationID,
+		CausationID:     env.CausationID,
+		AggregateType:   env.AggregateType,
+		Data:            data,
+	}
+
+	if !env.OccurredAt.IsZero() {
+		t := env.OccurredAt.UTC()
+		ce.Time = &t
+	}
+
+	if env.AggregateVersion != 0 {
+		ce.AggregateVersion = strconv.Itoa(env.AggregateVersion)
+	}
+
+	if len(env.Metadata) > 0 {
+		values := make(url.Values, len(env.Metadata))
+		for k, v := range env.Metadata {
+			values.Set(k, v)
+		}
+
+		ce.Metadata = values.Encode()
+	}
+
+	return ce, nil
+}
+
+func (ce cloudEvent) envelope(registry *events.Registry) (events.Event, error) {
+	if ce.SpecVersion != CloudEventsSpecVersion {
+		return nil, fmt.Errorf("unsupported cloudevents spec version `%s`", ce.SpecVersion)
+	}
+
+	if ce.ID == "" || ce.Type == "" || ce.Source == "" {
+		return nil, fmt.Errorf("could not unmarshal (cloudevents) message, missing required attributes")
+	}
+
+	name, raw, err := registry.Upcast(ce.Type, ce.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal (cloudevents) message data", err)
+	}
+
+	payload, err := registry.Decode(name, func(target interface{}) error {
+		return json.Unmarshal(raw, target)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal (cloudevents) message data", err)
+	}
+
+	env := events.Envelope{
+		EventID:       ce.ID,
+		EventType:     name,
+		AggregateID:   ce.Subject,
+		AggregateType: ce.AggregateType,
+		CorrelationID: ce.CorrelationID,
+		CausationID:   ce.CausationID,
+		Payload:       payload,
+	}
+
+	if ce.Time != nil {
+		env.OccurredAt = *ce.Time
+	}
+
+	if ce.AggregateVersion != "" {
+		v, aErr := strconv.Atoi(ce.AggregateVersion)
+		if aErr != nil {
+			return nil, fmt.Errorf("%w: invalid (cloudevents) aggregateversion attribute", aErr)
+		}
+
+		env.AggregateVersion = v
+	}
+
+	if ce.Metadata != "" {
+		values, qErr := url.ParseQuery(ce.Metadata)
+		if qErr != nil {
+			return nil, fmt.Errorf("%w: invalid (cloudevents) metadata attribute", qErr)
+		}
+
+		env.Metadata = make(map[string]string, len(values))
+		for k := range values {
+			env.Metadata[k] = values.Get(k)
+		}
+	}
+
+	return env, nil
+}
@@ -0,0 +1,125 @@
+package drain_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestCloudEvents(t *testing.T) {
+	reg := events.NewRegistry()
+	require.NoError(t, reg.RegisterName("com.example.fake", fakeMessage{}))
+
+	recorder := &events.BaseRecorder{}
+	recorder.SetAggregate(gofakeit.UUID(), "fake", 0)
+	recorder.RecordContext(
+		events.WithMetadata(
+			events.WithMetadata(
+				events.WithCorrelation(context.Background(), gofakeit.UUID(), gofakeit.UUID()),
+				"tenant", "acme",
+			),
+			"Trace Parent", "00-a&b=c",
+		),
+		fakeMessage{ID: gofakeit.UUID()},
+	)
+
+	env := recorder.Envelopes()[0]
+
+	t.Run("GIVEN a structured-mode marshaller WHEN marshalling an envelope THEN required attributes are populated", func(t *testing.T) {
+		b, err := drain.NewCloudEventsMarshaller(reg, "/tests")(env)
+		require.NoError(t, err)
+
+		var doc map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &doc))
+
+		require.Equal(t, "1.0", doc["specversion"])
+		require.Equal(t, env.EventID, doc["id"])
+		require.Equal(t, "com.example.fake", doc["type"])
+		require.Equal(t, "/tests", doc["source"])
+		require.Equal(t, env.AggregateID, doc["subject"])
+		require.Equal(t, "application/json", doc["datacontenttype"])
+		require.Equal(t, env.CorrelationID, doc["correlationid"])
+		require.Equal(t, "Trace+Parent=00-a%26b%3Dc&tenant=acme", doc["metadata"])
+		require.NotEmpty(t, doc["time"])
+		require.Equal(t, map[string]interface{}{"ID": env.Payload.(fakeMessage).ID}, doc["data"])
+
+		t.Run("WHEN unmarshalling it back THEN the original envelope is returned", func(t *testing.T) {
+			got, err := drain.NewCloudEventsUnmarshaller(reg)(b)
+			require.NoError(t, err)
+			requireSameEnvelope(t, env, got)
+		})
+	})
+
+	t.Run("GIVEN a binary-mode encoder WHEN encoding an envelope THEN attributes are returned as headers", func(t *testing.T) {
+		headers, body, err := drain.NewCloudEventsBinaryEncoder(reg, "/tests")(env)
+		require.NoError(t, err)
+
+		require.Equal(t, "1.0", headers["ce-specversion"])
+		require.Equal(t, env.EventID, headers["ce-id"])
+		require.Equal(t, "com.example.fake", headers["ce-type"])
+		require.Equal(t, "/tests", headers["ce-source"])
+		require.Equal(t, env.AggregateID, headers["ce-subject"])
+		require.Equal(t, "1", headers["ce-aggregateversion"])
+		require.Equal(t, "Trace+Parent=00-a%26b%3Dc&tenant=acme", headers["ce-metadata"])
+		require.Equal(t, "application/json", headers["content-type"])
+		require.JSONEq(t, `{"ID":"`+env.Payload.(fakeMessage).ID+`"}`, string(body))
+
+		t.Run("WHEN decoding it back THEN the original envelope is returned", func(t *testing.T) {
+			got, err := drain.NewCloudEventsBinaryDecoder(reg)(headers, body)
+			require.NoError(t, err)
+			requireSameEnvelope(t, env, got)
+		})
+	})
+
+	t.Run("GIVEN a plain event not wrapped in an envelope", func(t *testing.T) {
+		plain := fakeMessage{ID: gofakeit.UUID()}
+
+		t.Run("WHEN marshalling it THEN it fails", func(t *testing.T) {
+			_, err := drain.NewCloudEventsMarshaller(reg, "/tests")(plain)
+			require.ErrorIs(t, err, drain.ErrNotEnvelope)
+		})
+
+		t.Run("WHEN encoding it in binary mode THEN it fails", func(t *testing.T) {
+			_, _, err := drain.NewCloudEventsBinaryEncoder(reg, "/tests")(plain)
+			require.ErrorIs(t, err, drain.ErrNotEnvelope)
+		})
+	})
+
+	t.Run("GIVEN an envelope WHEN marshalling it twice THEN both documents are identical", func(t *testing.T) {
+		marshal := drain.NewCloudEventsMarshaller(reg, "/tests")
+
+		first, err := marshal(env)
+		require.NoError(t, err)
+
+		second, err := marshal(env)
+		require.NoError(t, err)
+		require.Equal(t, first, second)
+	})
+
+	t.Run("GIVEN a structured-mode unmarshaller WHEN unmarshalling a document missing required attributes THEN it fails", func(t *testing.T) {
+		_, err := drain.NewCloudEventsUnmarshaller(reg)([]byte(`{"specversion":"1.0","type":"com.example.fake","data":{}}`))
+		require.Error(t, err)
+	})
+}
+
+func requireSameEnvelope(t *testing.T, expected events.Envelope, got events.Event) {
+	t.Helper()
+
+	env, ok := got.(events.Envelope)
+	require.True(t, ok)
+
+	require.Equal(t, expected.EventID, env.EventID)
+	require.Equal(t, expected.AggregateID, env.AggregateID)
+	require.Equal(t, expected.AggregateType, env.AggregateType)
+	require.Equal(t, expected.AggregateVersion, env.AggregateVersion)
+	require.Equal(t, expected.CorrelationID, env.CorrelationID)
+	require.Equal(t, expected.CausationID, env.CausationID)
+	require.Equal(t, expected.Metadata, env.Metadata)
+	require.True(t, expected.OccurredAt.Equal(env.OccurredAt))
+	require.Equal(t, expected.Payload, env.Payload)
+}
@@ -0,0 +1,207 @@
+package drain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// jsonRecord is the wire representation used by registry-aware JSON
+// marshallers. It mirrors the JSON representation of events.Envelope, but the
+// payload is kept raw until its type is resolved by name.
+type jsonRecord struct {
+	EventID          string            `json:"eventId,omitempty"`
+	EventType        string            `json:"eventType"`
+	OccurredAt       *time.Time        `json:"occurredAt,omitempty"`
+	AggregateID      string            `json:"aggregateId,omitempty"`
+	AggregateType    string            `json:"aggregateType,omitempty"`
+	AggregateVersion int               `json:"aggregateVersion,omitempty"`
+	CorrelationID    string            `json:"correlationId,omitempty"`
+	CausationID      string            `json:"causationId,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	Payload          json.RawMessage   `json:"payload"`
+}
+
+// NewJSONMarshaller builds a JSON marshaller that embeds the name under which
+// the type of each message is registered in the given registry, so it can be
+// decoded back using NewJSONUnmarshaller.
+//
+// Messages are encoded as JSON objects shaped like events.Envelope, where the
+// `eventType` field holds the registered name. Envelopes keep all their
+// metadata, while plain events only include the `eventType` and `payload`
+// fields.
+func NewJSONMarshaller(registry *events.Registry) Marshaller[events.Event] {
+	return func(m events.Event) ([]byte, error) {
+		name, err := registry.NameOf(m)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not marshal (json) message", err)
+		}
+
+		payload, err := json.Marshal(events.Unwrap(m))
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not marshal (json) message payload of type `%s`", err, name)
+		}
+
+		record := jsonRecord{
+			EventType: name,
+			Payload:   payload,
+		}
+
+		if env, ok := m.(events.Envelope); ok {
+			occurredAt := env.OccurredAt
+
+			record.EventID = env.EventID
+			record.OccurredAt = &occurredAt
+			record.AggregateID = env.AggregateID
+			record.AggregateType = env.AggregateType
+			record.AggregateVersion = env.AggregateVersion
+			record.CorrelationID = env.CorrelationID
+			record.CausationID = env.CausationID
+			record.Metadata = env.Metadata
+		}
+
+		return json.Marshal(record)
+	}
+}
+
+// NewJSONUnmarshaller builds the inverse of NewJSONMarshaller. The payload is
+// upcasted using the registry upcasters, and decoded into the type registered
+// under the resulting name. Messages holding an event ID are returned as
+// events.Envelope, otherwise the bare payload is returned.
+func NewJSONUnmarshaller(registry *events.Registry) Unmarshaller[events.Event] {
+	return func(b []byte) (events.Event, error) {
+		var record jsonRecord
+
+		if err := json.Unmarshal(b, &record); err != nil {
+			return nil, fmt.Errorf("%w: could not unmarshal (json) message", err)
+		}
+
+		return record.decode(registry)
+	}
+}
+
+// decode upcasts the payload of the record, and decodes it into the type
+// registered under the resulting name.
+func (record jsonRecord) decode(registry *events.Registry) (events.Event, error) {
+	name, raw, err := registry.Upcast(record.EventType, record.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal (json) message payload", err)
+	}
+
+	payload, err := registry.Decode(name, func(target interface{}) error {
+		return json.Unmarshal(raw, target)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not unmarshal (json) message payload", err)
+	}
+
+	if record.EventID == "" {
+		return payload, nil
+	}
+
+	env := events.Envelope{
+		EventID:          record.EventID,
+		EventType:        name,
+		AggregateID:      record.AggregateID,
+		AggregateType:    record.AggregateType,
+		AggregateVersion: record.AggregateVersion,
+		CorrelationID:    record.CorrelationID,
+		CausationID:      record.CausationID,
+		Metadata:         record.Metadata,
+		Payload:          payload,
+	}
+
+	if record.OccurredAt != nil {
+		env.OccurredAt = *record.OccurredAt
+	}
+
+	return env, nil
+}
+
+// NewProtoMarshaller builds a proto marshaller that wraps each message in an
+// `Any` message, whose type URL refers to the full name of the message as
+// usual, i.e. `type.googleapis.com/<full name>`, so it can be decoded back
+// using NewProtoUnmarshaller or any other proto library. Messages must be
+// `proto.Message` instances registered in the given registry, and envelopes
+// are unwrapped, so only their payload is marshalled.
+func NewProtoMarshaller(registry *events.Registry) Marshaller[events.Event] {
+	return func(m events.Event) ([]byte, error) {
+		m = events.Unwrap(m)
+
+		pb, ok := m.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("could not marshal (proto) message of type `%T`, not a proto message", m)
+		}
+
+		name, err := registry.NameOf(m)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not marshal (proto) message", err)
+		}
+
+		anyMsg, err := anypb.New(pb)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not marshal (proto) message of type `%s`", err, name)
+		}
+
+		return proto.Marshal(anyMsg)
+	}
+}
+
+// NewProtoUnmarshaller builds the inverse of NewProtoMarshaller. Payloads are
+// upcasted using the registry upcasters before being decoded.
+//
+// The message a type URL refers to is resolved into the name its type is
+// registered under in the given registry. Messages whose type is not
+// registered are named after the last segment of their type URL, so upcasters
+// of old messages no longer registered must be registered under their full
+// name. This also decodes payloads whose type URL is a bare registry name.
+func NewProtoUnmarshaller(registry *events.Registry) Unmarshaller[events.Event] {
+	return func(b []byte) (events.Event, error) {
+		var anyMsg anypb.Any
+
+		if err := proto.Unmarshal(b, &anyMsg); err != nil {
+			return nil, fmt.Errorf("%w: could not unmarshal (proto) message", err)
+		}
+
+		name, raw, err := registry.Upcast(protoEventName(registry, anyMsg.GetTypeUrl()), anyMsg.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not unmarshal (proto) message", err)
+		}
+
+		event, err := registry.Decode(name, func(target interface{}) error {
+			pb, ok := target.(proto.Message)
+			if !ok {
+				return fmt.Errorf("type `%T` is not a proto message", target)
+			}
+
+			return proto.Unmarshal(raw, pb)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not unmarshal (proto) message", err)
+		}
+
+		return event, nil
+	}
+}
+
+// protoEventName resolves the registry name of the message the given type URL
+// refers to, see NewProtoUnmarshaller.
+func protoEventName(registry *events.Registry, typeURL string) string {
+	if mt, err := protoregistry.GlobalTypes.FindMessageByURL(typeURL); err == nil {
+		if name, nErr := registry.NameOf(mt.New().Interface()); nErr == nil {
+			return name
+		}
+	}
+
+	if i := strings.LastIndexByte(typeURL, '/'); i >= 0 {
+		return typeURL[i+1:]
+	}
+
+	return typeURL
+}
@@ -0,0 +1,154 @@
+package drain_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestRegistryMarshallers(t *testing.T) {
+	reg := events.NewRegistry()
+	require.NoError(t, reg.RegisterName("test.fake", fakeMessage{}))
+	require.NoError(t, reg.RegisterName("test.string", &wrapperspb.StringValue{}))
+
+	t.Run("GIVEN registry-aware JSON marshallers", func(t *testing.T) {
+		marshal := drain.NewJSONMarshaller(reg)
+		unmarshal := drain.NewJSONUnmarshaller(reg)
+
+		t.Run("WHEN a plain event is marshalled and unmarshalled THEN the concrete event is returned", func(t *testing.T) {
+			msg := fakeMessage{ID: gofakeit.UUID()}
+
+			b, err := marshal(msg)
+			require.NoError(t, err)
+			require.Contains(t, string(b), `"eventType":"test.fake"`)
+
+			got, err := unmarshal(b)
+			require.NoError(t, err)
+			require.Equal(t, msg, got)
+		})
+
+		t.Run("WHEN an envelope is marshalled and unmarshalled THEN the envelope and its metadata are returned", func(t *testing.T) {
+			ctx := events.WithCorrelation(context.Background(), gofakeit.UUID(), gofakeit.UUID())
+			env := events.Wrap(ctx, fakeMessage{ID: gofakeit.UUID()})
+
+			b, err := marshal(env)
+			require.NoError(t, err)
+
+			got, err := unmarshal(b)
+			require.NoError(t, err)
+
+			gotEnv, ok := got.(events.Envelope)
+			require.True(t, ok)
+			require.Equal(t, env.EventID, gotEnv.EventID)
+			require.Equal(t, "test.fake", gotEnv.EventType)
+			require.Equal(t, env.CorrelationID, gotEnv.CorrelationID)
+			require.True(t, env.OccurredAt.Equal(gotEnv.OccurredAt))
+			require.Equal(t, env.Payload, gotEnv.Payload)
+		})
+
+		t.Run("WHEN an unregistered event is marshalled THEN marshalling fails", func(t *testing.T) {
+			_, err := marshal("not registered")
+			require.ErrorIs(t, err, events.ErrEventNotRegistered)
+		})
+
+		t.Run("WHEN a message with an unknown name is unmarshalled THEN unmarshalling fails", func(t *testing.T) {
+			_, err := unmarshal([]byte(`{"eventType":"unknown","payload":{}}`))
+			require.ErrorIs(t, err, events.ErrEventNotRegistered)
+		})
+	})
+
+	t.Run("GIVEN registry-aware proto marshallers WHEN a proto event is marshalled and unmarshalled THEN the concrete event is returned", func(t *testing.T) {
+		marshal := drain.NewProtoMarshaller(reg)
+		unmarshal := drain.NewProtoUnmarshaller(reg)
+		msg := wrapperspb.String(gofakeit.Word())
+
+		b, err := marshal(events.Wrap(context.Background(), msg))
+		require.NoError(t, err)
+
+		got, err := unmarshal(b)
+		require.NoError(t, err)
+
+		gotPB, ok := got.(*wrapperspb.StringValue)
+		require.True(t, ok)
+		require.True(t, proto.Equal(msg, gotPB))
+	})
+
+	t.Run("GIVEN registry-aware proto marshallers", func(t *testing.T) {
+		marshal := drain.NewProtoMarshaller(reg)
+		unmarshal := drain.NewProtoUnmarshaller(reg)
+		msg := wrapperspb.String(gofakeit.Word())
+
+		t.Run("WHEN a proto event is marshalled THEN it is a standard Any message", func(t *testing.T) {
+			b, err := marshal(msg)
+			require.NoError(t, err)
+
+			var anyMsg anypb.Any
+
+			require.NoError(t, proto.Unmarshal(b, &anyMsg))
+			require.Equal(t, "type.googleapis.com/google.protobuf.StringValue", anyMsg.GetTypeUrl())
+
+			got, err := anyMsg.UnmarshalNew()
+			require.NoError(t, err)
+			require.True(t, proto.Equal(msg, got))
+		})
+
+		t.Run("WHEN an Any message named after the registry name is unmarshalled THEN the concrete event is returned", func(t *testing.T) {
+			value, err := proto.Marshal(msg)
+			require.NoError(t, err)
+
+			b, err := proto.Marshal(&anypb.Any{TypeUrl: "test.string", Value: value})
+			require.NoError(t, err)
+
+			got, err := unmarshal(b)
+			require.NoError(t, err)
+			require.True(t, proto.Equal(msg, got.(proto.Message)))
+		})
+	})
+}
+
+func TestRegistryUnmarshallerUpcasting(t *testing.T) {
+	t.Run("GIVEN a registry with the second version of an event AND an upcaster from version 1", func(t *testing.T) {
+		reg := events.NewRegistry()
+		require.NoError(t, reg.Register(itemAddedV2{}))
+		require.NoError(t, reg.RegisterUpcaster("item.added", func(payload []byte) ([]byte, error) {
+			var v1 struct {
+				ID string `json:"id"`
+			}
+
+			if err := json.Unmarshal(payload, &v1); err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(itemAddedV2{ItemID: v1.ID, Quantity: 1})
+		}))
+
+		t.Run("WHEN unmarshalling a stored version 1 envelope THEN it decodes into the current struct", func(t *testing.T) {
+			raw := []byte(`{"eventId":"e1","eventType":"item.added","occurredAt":"2023-01-01T00:00:00Z","payload":{"id":"i1"}}`)
+
+			got, err := drain.NewJSONUnmarshaller(reg)(raw)
+			require.NoError(t, err)
+
+			env, ok := got.(events.Envelope)
+			require.True(t, ok)
+			require.Equal(t, "item.added.v2", env.EventType)
+			require.Equal(t, itemAddedV2{ItemID: "i1", Quantity: 1}, env.Payload)
+		})
+	})
+}
+
+type itemAddedV2 struct {
+	ItemID   string `json:"itemId"`
+	Quantity int    `json:"quantity"`
+}
+
+func (itemAddedV2) EventName() string {
+	return events.VersionedName("item.added", 2)
+}
@@ -0,0 +1,329 @@
+package drain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+// EventDispatcher defines a component capable of dispatching events, such as
+// dispatcher.Dispatcher.
+type EventDispatcher interface {
+	Dispatch(ctx context.Context, event events.Event) error
+}
+
+// ReplayOption configures a ReplaySource.
+type ReplayOption func(*replayOptions)
+
+type replayOptions struct {
+	types map[string]struct{}
+	bases map[string]struct{}
+	from  time.Time
+	to    time.Time
+	speed float64
+
+	unmarshaller Unmarshaller[events.Event]
+}
+
+// WithReplayTypes only replays events whose type is registered under any of
+// the given names.
+func WithReplayTypes(names ...string) ReplayOption {
+	return func(o *replayOptions) {
+		if o.types == nil {
+			o.types = make(map[string]struct{}, len(names))
+			o.bases = make(map[string]struct{}, len(names))
+		}
+
+		for _, name := range names {
+			base, _ := events.ParseVersionedName(name)
+
+			o.types[name] = struct{}{}
+			o.bases[base] = struct{}{}
+		}
+	}
+}
+
+// WithReplayTimeRange only replays events that occurred within the given time
+// range, both ends inclusive. A zero value on any end leaves that end open.
+// Events not wrapped in an envelope have no occurrence time, so they are
+// skipped when a range is given.
+func WithReplayTimeRange(from, to time.Time) ReplayOption {
+	return func(o *replayOptions) {
+		o.from = from
+		o.to = to
+	}
+}
+
+// WithReplaySpeed controls the pace at which events are replayed, relative to
+// the time elapsed between their occurrences. For instance, a ratio of 1
+// replays events in real time, and a ratio of 10 replays them ten times
+// faster. A ratio of zero, the default, replays events as fast as possible.
+func WithReplaySpeed(ratio float64) ReplayOption {
+	return func(o *replayOptions) {
+		o.speed = ratio
+	}
+}
+
+// WithReplayUnmarshaller decodes every line using the given unmarshaller,
+// rather than as written by NewJSONMarshaller. Needed to replay logs written
+// using other marshallers, such as JSONMarshaller, whose lines carry no type
+// names. Lines are then filtered once decoded, so every line must be decodable,
+// and types are filtered by the name their events are registered under.
+func WithReplayUnmarshaller(unmarshaller Unmarshaller[events.Event]) ReplayOption {
+	return func(o *replayOptions) {
+		o.unmarshaller = unmarshaller
+	}
+}
+
+// ReplaySource reads back event logs written as JSON lines, such as the ones
+// produced by NewIOWriter along with NewJSONMarshaller, and replays them.
+// Lines are filtered before decoding their payload, so events filtered out
+// need not be registered. Logs written using other marshallers can be replayed
+// as well, see WithReplayUnmarshaller.
+type ReplaySource struct {
+	reader   *bufio.Reader
+	closer   io.Closer
+	registry *events.Registry
+	opts     replayOptions
+}
+
+// NewReplaySource builds a replay source that reads events from the given
+// reader, one JSON document per line, decoding them through the given
+// registry.
+func NewReplaySource(r io.Reader, registry *events.Registry, opts ...ReplayOption) *ReplaySource {
+	src := &ReplaySource{
+		reader:   bufio.NewReader(r),
+		registry: registry,
+	}
+
+	for _, opt := range opts {
+		opt(&src.opts)
+	}
+
+	return src
+}
+
+// NewFileReplaySource builds a replay source that reads events from the file
+// located at the given path. The source must be closed once done.
+func NewFileReplaySource(path string, registry *events.Registry, opts ...ReplayOption) (*ReplaySource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: replay source could not open file `%s`", err, path)
+	}
+
+	src := NewReplaySource(f, registry, opts...)
+	src.closer = f
+
+	return src, nil
+}
+
+// Close releases the resources held by the source, if any.
+func (s *ReplaySource) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	if err := s.closer.Close(); err != nil {
+		return fmt.Errorf("%w: replay source could not close", err)
+	}
+
+	return nil
+}
+
+// ReplayTo writes every matching event into the given writer, such as a Sink,
+// and returns the number of replayed events. Replaying stops at the first
+// error, or when the given context is cancelled.
+func (s *ReplaySource) ReplayTo(ctx context.Context, dst Writer[events.Event]) (int, error) {
+	return s.replay(ctx, func(_ context.Context, event events.Event) error {
+		return dst.Write(event)
+	})
+}
+
+// Dispatch dispatches every matching event through the given dispatcher, and
+// returns the number of replayed events. Replaying stops at the first error,
+// or when the given context is cancelled.
+func (s *ReplaySource) Dispatch(ctx context.Context, d EventDispatcher) (int, error) {
+	return s.replay(ctx, d.Dispatch)
+}
+
+func (s *ReplaySource) replay(ctx context.Context, deliver func(context.Context, events.Event) error) (int, error) {
+	var (
+		replayed int
+		line     int
+		previous time.Time
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		raw, rErr := s.reader.ReadBytes('\n')
+		if rErr != nil && !errors.Is(rErr, io.EOF) {
+			return replayed, fmt.Errorf("%w: replay source could not read line %d", rErr, line+1)
+		}
+
+		line++
+
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 {
+			event, matches, err := s.decode(raw)
+			if err != nil {
+				return replayed, fmt.Errorf("%w: replay source could not decode line %d", err, line)
+			}
+
+			if matches {
+				if err := s.pace(ctx, event, &previous); err != nil {
+					return replayed, err
+				}
+
+				if err := deliver(ctx, event); err != nil {
+					return replayed, fmt.Errorf("%w: replay source could not deliver event at line %d", err, line)
+				}
+
+				replayed++
+			}
+		}
+
+		if errors.Is(rErr, io.EOF) {
+			return replayed, nil
+		}
+	}
+}
+
+// decode decodes the given line into an event, unless the line is filtered
+// out, in which case its payload is left undecoded.
+func (s *ReplaySource) decode(raw []byte) (events.Event, bool, error) {
+	if s.opts.unmarshaller != nil {
+		event, err := s.opts.unmarshaller(raw)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return event, s.matchesEvent(event), nil
+	}
+
+	var record jsonRecord
+
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, false, fmt.Errorf("%w: could not unmarshal (json) message", err)
+	}
+
+	if !s.matches(record) {
+		return nil, false, nil
+	}
+
+	event, err := record.decode(s.registry)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return event, s.matchesType(event), nil
+}
+
+// matchesType whether the given decoded event is of any of the filtered types.
+func (s *ReplaySource) matchesType(event events.Event) bool {
+	if len(s.opts.types) == 0 {
+		return true
+	}
+
+	name, err := s.registry.NameOf(event)
+	if err != nil {
+		return false
+	}
+
+	_, ok := s.opts.types[name]
+
+	return ok
+}
+
+// matchesEvent whether the given decoded event matches the filters. Used when
+// lines are decoded by an unmarshaller, as their records are unknown.
+func (s *ReplaySource) matchesEvent(event events.Event) bool {
+	if !s.matchesType(event) {
+		return false
+	}
+
+	if s.opts.from.IsZero() && s.opts.to.IsZero() {
+		return true
+	}
+
+	env, ok := event.(events.Envelope)
+	if !ok || env.OccurredAt.IsZero() {
+		return false
+	}
+
+	if !s.opts.from.IsZero() && env.OccurredAt.Before(s.opts.from) {
+		return false
+	}
+
+	return s.opts.to.IsZero() || !env.OccurredAt.After(s.opts.to)
+}
+
+// matches whether the given record may match the filters. Types are matched by
+// base name, as records of older versions are only named after the filtered
+// type once upcasted.
+func (s *ReplaySource) matches(record jsonRecord) bool {
+	if len(s.opts.bases) > 0 {
+		base, _ := events.ParseVersionedName(record.EventType)
+		if _, ok := s.opts.bases[base]; !ok {
+			return false
+		}
+	}
+
+	if s.opts.from.IsZero() && s.opts.to.IsZero() {
+		return true
+	}
+
+	// Records without event ID are decoded as bare payloads, not envelopes,
+	// hence have no occurrence time.
+	if record.EventID == "" || record.OccurredAt == nil {
+		return false
+	}
+
+	if !s.opts.from.IsZero() && record.OccurredAt.Before(s.opts.from) {
+		return false
+	}
+
+	if !s.opts.to.IsZero() && record.OccurredAt.After(s.opts.to) {
+		return false
+	}
+
+	return true
+}
+
+// pace waits for the time elapsed between the previous event and the given
+// one, scaled by the configured speed ratio.
+func (s *ReplaySource) pace(ctx context.Context, event events.Event, previous *time.Time) error {
+	env, ok := event.(events.Envelope)
+	if s.opts.speed <= 0 || !ok || env.OccurredAt.IsZero() {
+		return nil
+	}
+
+	defer func() {
+		*previous = env.OccurredAt
+	}()
+
+	if previous.IsZero() || !env.OccurredAt.After(*previous) {
+		return nil
+	}
+
+	wait := time.Duration(float64(env.OccurredAt.Sub(*previous)) / s.opts.speed)
+	timer := time.NewTimer(wait)
+
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
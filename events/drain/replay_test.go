@@ -0,0 +1,186 @@
+package drain_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestReplaySource(t *testing.T) {
+	reg := events.NewRegistry()
+	require.NoError(t, reg.RegisterName("test.fake", fakeMessage{}))
+	require.NoError(t, reg.Register(itemAddedV2{}))
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := []events.Event{
+		events.Envelope{EventID: "e1", OccurredAt: start, Payload: fakeMessage{ID: "1"}},
+		events.Envelope{EventID: "e2", OccurredAt: start.Add(time.Second), Payload: itemAddedV2{ItemID: "i1"}},
+		events.Envelope{EventID: "e3", OccurredAt: start.Add(2 * time.Second), Payload: fakeMessage{ID: "2"}},
+		events.Envelope{EventID: "e4", OccurredAt: start.Add(3 * time.Second), Payload: itemAddedV2{ItemID: "i2"}},
+	}
+
+	var buf bytes.Buffer
+
+	writer := drain.NewIOWriter[events.Event](&buf, drain.NewJSONMarshaller(reg))
+	for _, e := range log {
+		require.NoError(t, writer.Write(e))
+	}
+
+	t.Run("GIVEN a JSON-lines log WHEN replaying it into a sink THEN every event is written in order", func(t *testing.T) {
+		sink := newTestSink[events.Event](t, len(log))
+
+		n, err := drain.NewReplaySource(bytes.NewReader(buf.Bytes()), reg).ReplayTo(context.Background(), sink)
+		require.NoError(t, err)
+		require.Equal(t, len(log), n)
+
+		for i := range log {
+			require.Equal(t, log[i].(events.Envelope).EventID, sink.messages[i].(events.Envelope).EventID)
+			require.Equal(t, events.Unwrap(log[i]), events.Unwrap(sink.messages[i]))
+		}
+	})
+
+	t.Run("GIVEN a JSON-lines log WHEN replaying with type and time range filters THEN only matching events are replayed", func(t *testing.T) {
+		sink := newTestSink[events.Event](t, 1)
+		src := drain.NewReplaySource(bytes.NewReader(buf.Bytes()), reg,
+			drain.WithReplayTypes("test.fake"),
+			drain.WithReplayTimeRange(start.Add(time.Second), time.Time{}),
+		)
+
+		n, err := src.ReplayTo(context.Background(), sink)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		require.Equal(t, "e3", sink.messages[0].(events.Envelope).EventID)
+	})
+
+	t.Run("GIVEN a JSON-lines file WHEN dispatching it THEN every event is dispatched", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+		src, err := drain.NewFileReplaySource(path, reg, drain.WithReplayTypes(itemAddedV2{}.EventName()))
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, src.Close())
+		}()
+
+		d := &recordingDispatcher{}
+
+		n, err := src.Dispatch(context.Background(), d)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Len(t, d.dispatched, 2)
+	})
+
+	t.Run("GIVEN a JSON-lines log WHEN replaying at a real-time ratio THEN the time between events is honored", func(t *testing.T) {
+		sink := newTestSink[events.Event](t, len(log))
+		began := time.Now()
+
+		_, err := drain.NewReplaySource(bytes.NewReader(buf.Bytes()), reg, drain.WithReplaySpeed(100)).
+			ReplayTo(context.Background(), sink)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, time.Since(began), 30*time.Millisecond)
+	})
+
+	t.Run("GIVEN a log with an unregistered event type", func(t *testing.T) {
+		unknown := []byte(`{"eventId":"e5","eventType":"test.unknown","occurredAt":"2023-01-01T00:00:10Z","payload":{}}` + "\n")
+		raw := append(append([]byte{}, buf.Bytes()...), unknown...)
+
+		t.Run("WHEN replaying only other types THEN it is skipped without failing", func(t *testing.T) {
+			sink := newTestSink[events.Event](t, 2)
+
+			n, err := drain.NewReplaySource(bytes.NewReader(raw), reg, drain.WithReplayTypes("test.fake")).
+				ReplayTo(context.Background(), sink)
+			require.NoError(t, err)
+			require.Equal(t, 2, n)
+		})
+
+		t.Run("WHEN replaying a time range excluding it THEN it is skipped without failing", func(t *testing.T) {
+			sink := newTestSink[events.Event](t, len(log))
+
+			n, err := drain.NewReplaySource(bytes.NewReader(raw), reg, drain.WithReplayTimeRange(time.Time{}, start.Add(5*time.Second))).
+				ReplayTo(context.Background(), sink)
+			require.NoError(t, err)
+			require.Equal(t, len(log), n)
+		})
+
+		t.Run("WHEN replaying every event THEN it fails", func(t *testing.T) {
+			sink := newTestSink[events.Event](t, len(log))
+
+			n, err := drain.NewReplaySource(bytes.NewReader(raw), reg).ReplayTo(context.Background(), sink)
+			require.ErrorIs(t, err, events.ErrEventNotRegistered)
+			require.Equal(t, len(log), n)
+		})
+	})
+
+	t.Run("GIVEN a log with an invalid line WHEN replaying THEN it fails", func(t *testing.T) {
+		sink := newTestSink[events.Event](t, len(log))
+		raw := append(append([]byte{}, buf.Bytes()...), []byte("not json\n")...)
+
+		n, err := drain.NewReplaySource(bytes.NewReader(raw), reg).ReplayTo(context.Background(), sink)
+		require.Error(t, err)
+		require.Equal(t, len(log), n)
+	})
+	t.Run("GIVEN a log written with the plain JSON marshaller", func(t *testing.T) {
+		var plain bytes.Buffer
+
+		plainWriter := drain.NewIOWriter[events.Event](&plain, drain.JSONMarshaller)
+		require.NoError(t, plainWriter.Write(fakeMessage{ID: "1"}))
+		require.NoError(t, plainWriter.Write(fakeMessage{ID: "2"}))
+
+		unmarshaller := func(raw []byte) (events.Event, error) {
+			var msg fakeMessage
+
+			return msg, json.Unmarshal(raw, &msg)
+		}
+
+		t.Run("WHEN replaying it as written by the registry marshaller THEN it fails", func(t *testing.T) {
+			sink := newTestSink[events.Event](t, 0)
+
+			_, err := drain.NewReplaySource(bytes.NewReader(plain.Bytes()), reg).ReplayTo(context.Background(), sink)
+			require.Error(t, err)
+		})
+
+		t.Run("WHEN replaying it with an unmarshaller AND a type filter THEN every event is replayed in order", func(t *testing.T) {
+			sink := newTestSink[events.Event](t, 2)
+			src := drain.NewReplaySource(bytes.NewReader(plain.Bytes()), reg,
+				drain.WithReplayUnmarshaller(unmarshaller),
+				drain.WithReplayTypes("test.fake"),
+			)
+
+			n, err := src.ReplayTo(context.Background(), sink)
+			require.NoError(t, err)
+			require.Equal(t, 2, n)
+			require.Equal(t, []events.Event{fakeMessage{ID: "1"}, fakeMessage{ID: "2"}}, sink.messages)
+		})
+
+		t.Run("WHEN replaying it with an unmarshaller AND a time range THEN events without occurrence time are skipped", func(t *testing.T) {
+			sink := newTestSink[events.Event](t, 0)
+			src := drain.NewReplaySource(bytes.NewReader(plain.Bytes()), reg,
+				drain.WithReplayUnmarshaller(unmarshaller),
+				drain.WithReplayTimeRange(start, time.Time{}),
+			)
+
+			n, err := src.ReplayTo(context.Background(), sink)
+			require.NoError(t, err)
+			require.Zero(t, n)
+		})
+	})
+}
+
+type recordingDispatcher struct {
+	dispatched []events.Event
+}
+
+func (r *recordingDispatcher) Dispatch(_ context.Context, event events.Event) error {
+	r.dispatched = append(r.dispatched, event)
+
+	return nil
+}
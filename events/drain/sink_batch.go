@@ -0,0 +1,303 @@
+package drain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events/observe"
+)
+
+// BatchWriter defines a component where batches of messages can be written
+// to, such as services accepting many records per request.
+type BatchWriter[M any] interface {
+	// WriteBatch writes the given messages at once. If no error is returned,
+	// the caller can assume that every message have been committed. A
+	// *BatchError tells which messages could not be written, the rest of
+	// them being committed. Any other error means none of them was.
+	WriteBatch(messages []M) error
+}
+
+// BatchFailure describes a message of a batch that could not be written.
+type BatchFailure[M any] struct {
+	// Index is the position of the message within the batch.
+	Index int
+
+	// Message is the message that could not be written.
+	Message M
+
+	// Err is the reason why the message could not be written.
+	Err error
+}
+
+// BatchError is returned by BatchWriter implementations when only some
+// messages of a batch could not be written.
+type BatchError[M any] struct {
+	Failures []BatchFailure[M]
+}
+
+// Error implements the error interface.
+func (e *BatchError[M]) Error() string {
+	if len(e.Failures) == 0 {
+		return "batch partially failed"
+	}
+
+	return fmt.Sprintf("%d messages of batch failed, first one with: %s", len(e.Failures), e.Failures[0].Err)
+}
+
+// Unwrap returns the errors of every failed message.
+func (e *BatchError[M]) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+
+	for i := range e.Failures {
+		errs[i] = e.Failures[i].Err
+	}
+
+	return errs
+}
+
+// BatchConfig defines when a batcher flushes the messages collected so far.
+// Zero values disable the corresponding limit.
+type BatchConfig[M any] struct {
+	// MaxMessages flushes once the batch holds this many messages.
+	MaxMessages int
+
+	// MaxBytes flushes once the batch holds this many bytes, as measured by
+	// Size. A message that would make the batch exceed this limit is kept
+	// for the next batch instead.
+	MaxBytes int
+
+	// Size returns the size in bytes of the given message. Required when
+	// MaxBytes is set.
+	Size func(M) int
+
+	// MaxDelay flushes once this time elapses since the first message of the
+	// batch was written, so messages do not wait indefinitely on idle sinks.
+	MaxDelay time.Duration
+}
+
+type batchSink[M any] struct {
+	*baseSink
+	dst     BatchWriter[M]
+	config  BatchConfig[M]
+	onError WriteErrorFn[M]
+	opts    sinkOptions
+
+	mu    sync.Mutex
+	batch []M
+	bytes int
+	timer Timer
+	gen   uint64
+}
+
+// NewBatcher returns a sink that collects written messages into batches, and
+// writes them into the given writer as dictated by the given config. Batches
+// are also flushed when the sink is closed, which closes the given writer as
+// well if it is an io.Closer.
+//
+// Messages are written to the writer in the same order they were written to
+// the sink, and writes block while a batch is being flushed. Messages failing
+// to be written are given to onError, if not nil, along with their error, once
+// the flush is over, so onError may write them back into the sink.
+func NewBatcher[M any](dst BatchWriter[M], config BatchConfig[M], onError WriteErrorFn[M], opts ...SinkOption) (Sink[M], error) {
+	if dst == nil {
+		return nil, fmt.Errorf("a valid batch writer must be provided")
+	}
+
+	if config.MaxBytes > 0 && config.Size == nil {
+		return nil, fmt.Errorf("a size function must be provided when limiting batches by bytes")
+	}
+
+	if onError == nil {
+		onError = noopWriteError[M]
+	}
+
+	return &batchSink[M]{
+		baseSink: newCloseTrait(),
+		dst:      dst,
+		config:   config,
+		onError:  onError,
+		opts:     newSinkOptions("", opts...),
+	}, nil
+}
+
+// Write adds the given message to the current batch, flushing it if any limit
+// is reached.
+func (bs *batchSink[M]) Write(message M) error {
+	bs.mu.Lock()
+	results, err := bs.add(message)
+	bs.mu.Unlock()
+
+	// failures are reported once unlocked, so onError may write into this sink.
+	bs.report(results...)
+
+	return err
+}
+
+// add adds the given message to the current batch, and returns the results of
+// the batches flushed meanwhile, if any. Must be called while holding the lock.
+func (bs *batchSink[M]) add(message M) ([]*batchResult[M], error) {
+	if bs.baseSink.IsClosed() {
+		return nil, fmt.Errorf("%w: batcher sink could not write message %T", ErrSinkClosed, message)
+	}
+
+	bs.opts.observer.Dispatched(bs.opts.labels(componentBatcher, message))
+
+	var (
+		size    int
+		results []*batchResult[M]
+	)
+
+	if bs.config.MaxBytes > 0 {
+		size = bs.config.Size(message)
+
+		if len(bs.batch) > 0 && bs.bytes+size > bs.config.MaxBytes {
+			results = append(results, bs.flush())
+		}
+	}
+
+	bs.batch = append(bs.batch, message)
+	bs.bytes += size
+
+	if len(bs.batch) == 1 && bs.config.MaxDelay > 0 {
+		gen := bs.gen
+		bs.timer = bs.opts.clock.AfterFunc(bs.config.MaxDelay, func() {
+			bs.expire(gen)
+		})
+	}
+
+	if (bs.config.MaxMessages > 0 && len(bs.batch) >= bs.config.MaxMessages) ||
+		(bs.config.MaxBytes > 0 && bs.bytes >= bs.config.MaxBytes) {
+		return append(results, bs.flush()), nil
+	}
+
+	bs.opts.observer.QueueDepth(bs.depthLabels(), len(bs.batch))
+
+	return results, nil
+}
+
+// Close flushes the current batch, and closes the underlying writer if it is
+// an io.Closer. Closing an already closed sink fails with ErrSinkClosed.
+func (bs *batchSink[M]) Close() error {
+	bs.mu.Lock()
+
+	if bs.baseSink.IsClosed() {
+		bs.mu.Unlock()
+
+		return fmt.Errorf("%w: batcher sink could not close", ErrSinkClosed)
+	}
+
+	result := bs.flush()
+	_ = bs.baseSink.Close()
+	bs.mu.Unlock()
+
+	bs.report(result)
+
+	if closer, ok := bs.dst.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("%w: batcher sink could not close underlying writer", err)
+		}
+	}
+
+	return nil
+}
+
+// expire flushes the batch the timer of the given generation was started for,
+// unless it was already flushed.
+func (bs *batchSink[M]) expire(gen uint64) {
+	bs.mu.Lock()
+
+	if gen != bs.gen || bs.baseSink.IsClosed() {
+		bs.mu.Unlock()
+
+		return
+	}
+
+	result := bs.flush()
+	bs.mu.Unlock()
+
+	bs.report(result)
+}
+
+// batchResult holds the outcome of writing a batch into the underlying writer.
+type batchResult[M any] struct {
+	batch   []M
+	err     error
+	elapsed time.Duration
+}
+
+// flush writes the current batch into the underlying writer, and returns its
+// result, nil if the batch was empty. Must be called while holding the lock.
+func (bs *batchSink[M]) flush() *batchResult[M] {
+	if bs.timer != nil {
+		bs.timer.Stop()
+		bs.timer = nil
+	}
+
+	bs.gen++
+
+	if len(bs.batch) == 0 {
+		return nil
+	}
+
+	batch := bs.batch
+	bs.batch = nil
+	bs.bytes = 0
+
+	bs.opts.observer.QueueDepth(bs.depthLabels(), 0)
+
+	start := bs.opts.clock.Now()
+	err := bs.dst.WriteBatch(batch)
+
+	return &batchResult[M]{
+		batch:   batch,
+		err:     err,
+		elapsed: bs.opts.clock.Now().Sub(start),
+	}
+}
+
+// report notifies the observer and onError about the outcome of every message
+// of the given results. Must be called without holding the lock.
+func (bs *batchSink[M]) report(results ...*batchResult[M]) {
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+
+		failed := make(map[int]error)
+
+		var bErr *BatchError[M]
+
+		switch {
+		case result.err == nil:
+		case errors.As(result.err, &bErr):
+			for _, f := range bErr.Failures {
+				failed[f.Index] = f.Err
+			}
+		default:
+			for i := range result.batch {
+				failed[i] = result.err
+			}
+		}
+
+		for i, message := range result.batch {
+			labels := bs.opts.labels(componentBatcher, message)
+
+			if errM, isFailed := failed[i]; isFailed {
+				bs.opts.observer.Failed(labels, errM)
+				bs.opts.observer.Dropped(labels, errM)
+				bs.onError(message, errM)
+
+				continue
+			}
+
+			bs.opts.observer.Delivered(labels, result.elapsed)
+		}
+	}
+}
+
+func (bs *batchSink[M]) depthLabels() observe.Labels {
+	return observe.Labels{Component: componentBatcher, Name: bs.opts.name}
+}
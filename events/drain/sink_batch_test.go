@@ -0,0 +1,300 @@
+package drain_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestBatcher(t *testing.T) {
+	t.Run("GIVEN a batcher flushing every 3 messages or every second", func(t *testing.T) {
+		clock := newFakeClock()
+		dst := &batchRecorder{}
+
+		sink, err := drain.NewBatcher[string](dst, drain.BatchConfig[string]{
+			MaxMessages: 3,
+			MaxDelay:    time.Second,
+		}, nil, drain.WithClock(clock))
+		require.NoError(t, err)
+
+		t.Run("WHEN writing 3 messages THEN they are flushed as a single batch", func(t *testing.T) {
+			for _, m := range []string{"a", "b", "c"} {
+				require.NoError(t, sink.Write(m))
+			}
+
+			require.Equal(t, [][]string{{"a", "b", "c"}}, dst.written())
+		})
+
+		t.Run("WHEN writing fewer messages THEN they are not flushed until the delay elapses", func(t *testing.T) {
+			require.NoError(t, sink.Write("d"))
+
+			clock.Advance(500 * time.Millisecond)
+			require.NoError(t, sink.Write("e"))
+			require.Len(t, dst.written(), 1)
+
+			clock.Advance(500 * time.Millisecond)
+			require.Equal(t, [][]string{{"a", "b", "c"}, {"d", "e"}}, dst.written())
+		})
+
+		t.Run("WHEN a batch is flushed by size THEN its timer does not flush the next batch early", func(t *testing.T) {
+			clock.Advance(100 * time.Millisecond)
+
+			for _, m := range []string{"f", "g", "h", "i"} {
+				require.NoError(t, sink.Write(m))
+			}
+
+			clock.Advance(900 * time.Millisecond)
+			require.Len(t, dst.written(), 3)
+
+			clock.Advance(100 * time.Millisecond)
+			require.Equal(t, []string{"i"}, dst.written()[3])
+		})
+
+		t.Run("WHEN closing THEN pending messages are flushed AND the writer is closed", func(t *testing.T) {
+			require.NoError(t, sink.Write("j"))
+			require.NoError(t, sink.Close())
+
+			require.Equal(t, []string{"j"}, dst.written()[4])
+			require.True(t, dst.closed)
+			require.ErrorIs(t, sink.Write("k"), drain.ErrSinkClosed)
+		})
+
+		t.Run("WHEN closing again THEN it fails", func(t *testing.T) {
+			require.ErrorIs(t, sink.Close(), drain.ErrSinkClosed)
+		})
+	})
+
+	t.Run("GIVEN a batcher limited by bytes", func(t *testing.T) {
+		dst := &batchRecorder{}
+
+		sink, err := drain.NewBatcher[string](dst, drain.BatchConfig[string]{
+			MaxBytes: 5,
+			Size:     func(m string) int { return len(m) },
+		}, nil, drain.WithClock(newFakeClock()))
+		require.NoError(t, err)
+
+		t.Run("WHEN a message does not fit in the current batch THEN the batch is flushed before adding it", func(t *testing.T) {
+			require.NoError(t, sink.Write("aa"))
+			require.NoError(t, sink.Write("bb"))
+			require.NoError(t, sink.Write("cc"))
+			require.NoError(t, sink.Write("ddd"))
+
+			require.Equal(t, [][]string{{"aa", "bb"}, {"cc", "ddd"}}, dst.written())
+		})
+
+		t.Run("WHEN a message exceeds the limit on its own THEN it is flushed alone", func(t *testing.T) {
+			require.NoError(t, sink.Write("eeeeeee"))
+			require.Equal(t, []string{"eeeeeee"}, dst.written()[2])
+		})
+	})
+
+	t.Run("GIVEN a batcher over a writer failing some messages", func(t *testing.T) {
+		errRejected := errors.New("rejected")
+		errDown := errors.New("down")
+		dst := &batchRecorder{
+			fail: func(batch []string) error {
+				if batch[0] == "down" {
+					return errDown
+				}
+
+				var failures []drain.BatchFailure[string]
+
+				for i, m := range batch {
+					if m == "bad" {
+						failures = append(failures, drain.BatchFailure[string]{Index: i, Message: m, Err: errRejected})
+					}
+				}
+
+				if failures != nil {
+					return &drain.BatchError[string]{Failures: failures}
+				}
+
+				return nil
+			},
+		}
+
+		var (
+			mu     sync.Mutex
+			failed = map[string]error{}
+		)
+
+		sink, err := drain.NewBatcher[string](dst, drain.BatchConfig[string]{MaxMessages: 3}, func(m string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			failed[m] = err
+		}, drain.WithClock(newFakeClock()))
+		require.NoError(t, err)
+
+		t.Run("WHEN a batch partially fails THEN only the failed messages are given to the error callback", func(t *testing.T) {
+			for _, m := range []string{"ok", "bad", "fine"} {
+				require.NoError(t, sink.Write(m))
+			}
+
+			require.Equal(t, map[string]error{"bad": errRejected}, failed)
+		})
+
+		t.Run("WHEN a batch fails as a whole THEN every message is given to the error callback", func(t *testing.T) {
+			require.NoError(t, sink.Write("down"))
+			require.NoError(t, sink.Write("other"))
+			require.NoError(t, sink.Close())
+
+			require.Equal(t, map[string]error{"bad": errRejected, "down": errDown, "other": errDown}, failed)
+		})
+	})
+	t.Run("GIVEN a batcher whose error callback writes failed messages back into it", func(t *testing.T) {
+		dst := &batchRecorder{
+			fail: func(batch []string) error {
+				if batch[0] == "retry" {
+					return errors.New("rejected")
+				}
+
+				return nil
+			},
+		}
+
+		var sink drain.Sink[string]
+
+		sink, err := drain.NewBatcher[string](dst, drain.BatchConfig[string]{MaxMessages: 1}, func(m string, err error) {
+			require.NoError(t, sink.Write("retried"))
+		}, drain.WithClock(newFakeClock()))
+		require.NoError(t, err)
+
+		t.Run("WHEN a batch fails THEN it does not deadlock AND the message is written back", func(t *testing.T) {
+			written := make(chan error, 1)
+
+			go func() {
+				written <- sink.Write("retry")
+			}()
+
+			select {
+			case wErr := <-written:
+				require.NoError(t, wErr)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "write deadlocked")
+			}
+
+			require.Equal(t, [][]string{{"retry"}, {"retried"}}, dst.written())
+		})
+	})
+}
+
+// batchRecorder is a batch writer recording every batch, optionally failing
+// them as dictated by fail.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]string
+	fail    func([]string) error
+	closed  bool
+}
+
+func (r *batchRecorder) WriteBatch(messages []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches = append(r.batches, append([]string{}, messages...))
+
+	if r.fail != nil {
+		return r.fail(messages)
+	}
+
+	return nil
+}
+
+func (r *batchRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+
+	return nil
+}
+
+func (r *batchRecorder) written() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([][]string{}, r.batches...)
+}
+
+// fakeClock is a clock whose time only moves when told to. Timers fire
+// synchronously within Advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Time
+	fn      func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) drain.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	timer := &fakeTimer{at: c.now.Add(d), fn: f}
+	c.timers = append(c.timers, timer)
+
+	return &fakeTimerHandle{clock: c, timer: timer}
+}
+
+// Advance moves the clock forward, firing every timer due in the meantime.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+
+	var due []*fakeTimer
+
+	pending := c.timers[:0]
+
+	for _, timer := range c.timers {
+		switch {
+		case timer.stopped:
+		case !timer.at.After(c.now):
+			timer.stopped = true
+			due = append(due, timer)
+		default:
+			pending = append(pending, timer)
+		}
+	}
+
+	c.timers = pending
+	c.mu.Unlock()
+
+	for _, timer := range due {
+		timer.fn()
+	}
+}
+
+type fakeTimerHandle struct {
+	clock *fakeClock
+	timer *fakeTimer
+}
+
+func (h *fakeTimerHandle) Stop() bool {
+	h.clock.mu.Lock()
+	defer h.clock.mu.Unlock()
+
+	wasPending := !h.timer.stopped
+	h.timer.stopped = true
+
+	return wasPending
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
 // BroadcasterSink sends messages to multiple, reliable Sinks. The goal of this
@@ -30,6 +31,7 @@ type broadcaster[M any] struct {
 
 	wErrHandler WriteErrorFn[M]
 	closeOnce   sync.Once
+	opts        sinkOptions
 }
 
 type bcConfigureRequest[M any] struct {
@@ -42,6 +44,12 @@ type bcConfigureRequest[M any] struct {
 // Generally, the sink should accept all messages and deal with reliability on
 // its own. Use of QueueSink and RetryingSink should be used here.
 func NewBroadcaster[M any](wErrHandler WriteErrorFn[M], to ...Sink[M]) BroadcasterSink[M] {
+	return NewBroadcasterWithOptions(wErrHandler, to)
+}
+
+// NewBroadcasterWithOptions is like NewBroadcaster, but also accepts sink
+// options.
+func NewBroadcasterWithOptions[M any](wErrHandler WriteErrorFn[M], to []Sink[M], opts ...SinkOption) BroadcasterSink[M] {
 	b := &broadcaster[M]{
 		baseSink:    newCloseTrait(),
 		sinks:       to,
@@ -49,6 +57,7 @@ func NewBroadcaster[M any](wErrHandler WriteErrorFn[M], to ...Sink[M]) Broadcast
 		adds:        make(chan bcConfigureRequest[M]),
 		removes:     make(chan bcConfigureRequest[M]),
 		wErrHandler: wErrHandler,
+		opts:        newSinkOptions("", opts...),
 	}
 
 	// Start the broadcaster
@@ -69,6 +78,7 @@ func (b *broadcaster[M]) Write(message M) error {
 	case <-b.Closed():
 		return fmt.Errorf("%w: broadcaster sink failed write message %T", ErrSinkClosed, message)
 	case b.messages <- message:
+		b.opts.observer.Dispatched(b.opts.labels(componentBroadcaster, message))
 	}
 
 	return nil
@@ -120,7 +130,11 @@ func (b *broadcaster[M]) run() {
 		case <-b.Closed():
 			return
 		case m := <-b.messages:
+			labels := b.opts.labels(componentBroadcaster, m)
+
 			for _, sink := range b.sinks {
+				start := time.Now()
+
 				if err := sink.Write(m); err != nil {
 					if errors.Is(err, ErrSinkClosed) {
 						// remove closed sinks
@@ -129,8 +143,13 @@ func (b *broadcaster[M]) run() {
 						continue
 					}
 
+					b.opts.observer.Failed(labels, err)
 					b.wErrHandler(m, err)
+
+					continue
 				}
+
+				b.opts.observer.Delivered(labels, time.Since(start))
 			}
 		case request := <-b.adds:
 			var found bool
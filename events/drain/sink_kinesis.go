@@ -3,11 +3,11 @@ package drain
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
-	"github.com/tangelo-labs/go-domain"
 )
 
 // KinesisAPI represents a Kinesis client for sending messages.
@@ -22,15 +22,25 @@ type kinesisSink[M any] struct {
 	marshaller Marshaller[M]
 	timeout    time.Duration
 	onError    WriteErrorFn[M]
+	opts       sinkOptions
+	keys       kinesisKeys[M]
+
+	// sequences holds the last sequence number of recently used partition
+	// keys, when sequence ordering is enabled.
+	sequences *sequenceCache
+	mu        sync.Mutex
 }
 
 // NewKinesisSink builds a new sink that sends messages to a Kinesis Stream.
+// Observations of the sink are named after the stream, unless told otherwise
+// with WithName.
 func NewKinesisSink[M any](
 	streamName string,
 	api KinesisAPI,
 	marshaller Marshaller[M],
 	timeout time.Duration,
 	onError WriteErrorFn[M],
+	opts ...KinesisOption[M],
 ) (Sink[M], error) {
 	if streamName == "" {
 		return nil, fmt.Errorf("a kinesis stream name must be provided")
@@ -52,14 +62,24 @@ func NewKinesisSink[M any](
 		onError = noopWriteError[M]
 	}
 
-	return &kinesisSink[M]{
+	ko := newKinesisOptions(opts...)
+
+	k := &kinesisSink[M]{
 		baseSink:   newCloseTrait(),
 		streamName: streamName,
 		kinesis:    api,
 		marshaller: marshaller,
 		timeout:    timeout,
 		onError:    onError,
-	}, nil
+		opts:       newSinkOptions(streamName, ko.sink...),
+		keys:       ko.keys,
+	}
+
+	if ko.sequenceOrdering {
+		k.sequences = newSequenceCache(KinesisSequenceKeys)
+	}
+
+	return k, nil
 }
 
 func (k *kinesisSink[M]) Write(message M) error {
@@ -67,19 +87,44 @@ func (k *kinesisSink[M]) Write(message M) error {
 		return fmt.Errorf("%w: writer sink could not write message %T", ErrSinkClosed, message)
 	}
 
+	labels := k.opts.labels(componentKinesis, message)
+	k.opts.observer.Dispatched(labels)
+
 	ctx, cancelFunc := context.WithTimeout(context.Background(), k.timeout)
 	defer cancelFunc()
 
 	data, err := k.marshaller(message)
 	if err != nil {
+		k.opts.observer.Failed(labels, err)
+
 		return err
 	}
 
-	if _, err = k.kinesis.PutRecord(ctx, &kinesis.PutRecordInput{
-		StreamName:   aws.String(k.streamName),
-		PartitionKey: aws.String(domain.NewID().String()),
-		Data:         data,
-	}); err != nil {
+	key, keyed := k.keys.partitionKey(message)
+	input := &kinesis.PutRecordInput{
+		StreamName:      aws.String(k.streamName),
+		PartitionKey:    aws.String(key),
+		ExplicitHashKey: k.keys.hashKey(message),
+		Data:            data,
+	}
+
+	// random keys are never chained, so they do not evict the keys that are.
+	chained := k.sequences != nil && keyed
+	if chained {
+		k.mu.Lock()
+		defer k.mu.Unlock()
+
+		if seq, ok := k.sequences.get(key); ok {
+			input.SequenceNumberForOrdering = aws.String(seq)
+		}
+	}
+
+	start := time.Now()
+
+	out, err := k.kinesis.PutRecord(ctx, input)
+	if err != nil {
+		k.opts.observer.Failed(labels, err)
+
 		if k.onError != nil {
 			k.onError(message, err)
 		}
@@ -87,5 +132,11 @@ func (k *kinesisSink[M]) Write(message M) error {
 		return err
 	}
 
+	k.opts.observer.Delivered(labels, time.Since(start))
+
+	if chained && out != nil && out.SequenceNumber != nil {
+		k.sequences.set(key, *out.SequenceNumber)
+	}
+
 	return nil
 }
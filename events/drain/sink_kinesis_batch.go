@@ -0,0 +1,306 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+)
+
+// Limits of a single PutRecords request, as documented by AWS.
+const (
+	// KinesisMaxBatchRecords is the maximum number of records per request.
+	KinesisMaxBatchRecords = 500
+
+	// KinesisMaxBatchBytes is the maximum size of a request, counting data
+	// and partition keys of every record.
+	KinesisMaxBatchBytes = 5 << 20
+
+	// KinesisMaxRecordBytes is the maximum size of a record, counting its
+	// data and partition key.
+	KinesisMaxRecordBytes = 1 << 20
+)
+
+// ErrKinesisRecordFailed is reported for messages that could not be put into
+// a Kinesis stream, once retries are exhausted.
+var ErrKinesisRecordFailed = errors.New("kinesis record failed")
+
+// KinesisBatchAPI represents a Kinesis client for sending batches of messages.
+type KinesisBatchAPI interface {
+	PutRecords(ctx context.Context, params *kinesis.PutRecordsInput, optFns ...func(*kinesis.Options)) (*kinesis.PutRecordsOutput, error)
+}
+
+// KinesisBatchConfig configures a Kinesis batch sink. Zero values fall back
+// to defaults.
+type KinesisBatchConfig struct {
+	// FlushInterval is the maximum time messages wait to be sent. Defaults
+	// to one second.
+	FlushInterval time.Duration
+
+	// MaxAttempts is the number of times a record is sent before giving up
+	// on it. Defaults to 3.
+	MaxAttempts int
+
+	// Backoff configures the wait between attempts. Defaults to
+	// DefaultExponentialBackoffConfig.
+	Backoff ExponentialBackoffConfig
+}
+
+type kinesisBatchWriter[M any] struct {
+	streamName string
+	kinesis    KinesisBatchAPI
+	marshaller Marshaller[M]
+	timeout    time.Duration
+	config     KinesisBatchConfig
+	clock      Clock
+	keys       kinesisKeys[M]
+
+	// closing is closed once the sink is closed, so backoffs stop delaying it.
+	closing chan struct{}
+	once    sync.Once
+}
+
+// kinesisBatchSink stops the backoffs of its writer before closing the
+// batcher, as the latter waits for the batch being written, if any.
+type kinesisBatchSink[M any] struct {
+	Sink[M]
+	writer *kinesisBatchWriter[M]
+}
+
+// kinesisEntry is a record to be put, along with the position of its message
+// within the batch being written.
+type kinesisEntry struct {
+	index  int
+	record types.PutRecordsRequestEntry
+	size   int
+}
+
+// NewKinesisBatchSink builds a new sink that sends messages to a Kinesis
+// Stream in batches, using PutRecords requests that respect the limits of
+// Kinesis, see KinesisMaxBatchRecords and KinesisMaxBatchBytes.
+//
+// Only the records rejected by Kinesis are retried, backing off between
+// attempts. Hence, retried records land after the ones following them in
+// their batch, so per partition key order is not preserved on retries, see
+// WithPartitionKey. Records still failing once attempts are exhausted, as well as
+// messages that cannot be marshalled, are given to onError. Once the sink is
+// being closed, remaining attempts are made without backing off, so closing
+// is not delayed by backoffs. See NewBatcher for details on batching.
+func NewKinesisBatchSink[M any](
+	streamName string,
+	api KinesisBatchAPI,
+	marshaller Marshaller[M],
+	timeout time.Duration,
+	config KinesisBatchConfig,
+	onError WriteErrorFn[M],
+	opts ...KinesisOption[M],
+) (Sink[M], error) {
+	if streamName == "" {
+		return nil, fmt.Errorf("a kinesis stream name must be provided")
+	}
+
+	if api == nil {
+		return nil, fmt.Errorf("a valid kinesis client must be provided")
+	}
+
+	if marshaller == nil {
+		return nil, fmt.Errorf("a valid marshaller function must be provided")
+	}
+
+	if timeout < time.Second {
+		return nil, fmt.Errorf("a timeout greater or equal than 1 second must be provided, got %s", timeout)
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+
+	if config.Backoff == (ExponentialBackoffConfig{}) {
+		config.Backoff = DefaultExponentialBackoffConfig
+	}
+
+	ko := newKinesisOptions(opts...)
+	if ko.sequenceOrdering {
+		return nil, fmt.Errorf("sequence ordering is not supported by kinesis batch sinks")
+	}
+
+	o := newSinkOptions(streamName, ko.sink...)
+
+	writer := &kinesisBatchWriter[M]{
+		streamName: streamName,
+		kinesis:    api,
+		marshaller: marshaller,
+		timeout:    timeout,
+		config:     config,
+		clock:      o.clock,
+		keys:       ko.keys,
+		closing:    make(chan struct{}),
+	}
+
+	batcher, err := NewBatcher[M](writer, BatchConfig[M]{
+		MaxMessages: KinesisMaxBatchRecords,
+		MaxDelay:    config.FlushInterval,
+	}, onError, append([]SinkOption{WithName(streamName)}, ko.sink...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kinesisBatchSink[M]{Sink: batcher, writer: writer}, nil
+}
+
+// Close stops backing off between attempts, and closes the batcher, which
+// flushes the current batch.
+func (k *kinesisBatchSink[M]) Close() error {
+	k.writer.once.Do(func() { close(k.writer.closing) })
+
+	return k.Sink.Close()
+}
+
+// WriteBatch marshals the given messages, and puts them into the stream using
+// as many requests as needed to stay within limits. Failures are reported in
+// the order of their messages.
+func (k *kinesisBatchWriter[M]) WriteBatch(messages []M) error {
+	var (
+		failures []BatchFailure[M]
+		entries  = make([]kinesisEntry, 0, len(messages))
+	)
+
+	for i := range messages {
+		data, err := k.marshaller(messages[i])
+		if err != nil {
+			failures = append(failures, BatchFailure[M]{Index: i, Message: messages[i], Err: err})
+
+			continue
+		}
+
+		key, _ := k.keys.partitionKey(messages[i])
+		entry := kinesisEntry{
+			index: i,
+			record: types.PutRecordsRequestEntry{
+				Data:            data,
+				PartitionKey:    aws.String(key),
+				ExplicitHashKey: k.keys.hashKey(messages[i]),
+			},
+			size: len(data) + len(key),
+		}
+
+		if entry.size > KinesisMaxRecordBytes {
+			failures = append(failures, BatchFailure[M]{
+				Index:   i,
+				Message: messages[i],
+				Err:     fmt.Errorf("%w: record of %d bytes exceeds the limit of %d bytes", ErrKinesisRecordFailed, entry.size, KinesisMaxRecordBytes),
+			})
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	for len(entries) > 0 {
+		n, size := 0, 0
+
+		for n < len(entries) && n < KinesisMaxBatchRecords && size+entries[n].size <= KinesisMaxBatchBytes {
+			size += entries[n].size
+			n++
+		}
+
+		for index, err := range k.put(entries[:n]) {
+			failures = append(failures, BatchFailure[M]{Index: index, Message: messages[index], Err: err})
+		}
+
+		entries = entries[n:]
+	}
+
+	if len(failures) > 0 {
+		sort.Slice(failures, func(i, j int) bool {
+			return failures[i].Index < failures[j].Index
+		})
+
+		return &BatchError[M]{Failures: failures}
+	}
+
+	return nil
+}
+
+// put sends the given entries, retrying the ones that fail, and returns the
+// errors of the ones that could not be sent, by message index.
+func (k *kinesisBatchWriter[M]) put(entries []kinesisEntry) map[int]error {
+	backoff := &exponentialBackoffStrategy[M]{config: k.config.Backoff}
+	failed := make(map[int]error)
+
+	for attempt := 1; ; attempt++ {
+		for i := range entries {
+			delete(failed, entries[i].index)
+		}
+
+		records := make([]types.PutRecordsRequestEntry, len(entries))
+		for i := range entries {
+			records[i] = entries[i].record
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
+		out, err := k.kinesis.PutRecords(ctx, &kinesis.PutRecordsInput{
+			StreamName: aws.String(k.streamName),
+			Records:    records,
+		})
+		cancel()
+
+		var retry []kinesisEntry
+
+		switch {
+		case err != nil:
+			for i := range entries {
+				failed[entries[i].index] = err
+			}
+
+			retry = entries
+		case out.FailedRecordCount != nil && *out.FailedRecordCount > 0:
+			for i := range out.Records {
+				if i >= len(entries) || out.Records[i].ErrorCode == nil {
+					continue
+				}
+
+				failed[entries[i].index] = fmt.Errorf("%w: %s: %s",
+					ErrKinesisRecordFailed,
+					aws.ToString(out.Records[i].ErrorCode),
+					aws.ToString(out.Records[i].ErrorMessage),
+				)
+
+				retry = append(retry, entries[i])
+			}
+		}
+
+		if len(retry) == 0 || attempt >= k.config.MaxAttempts {
+			return failed
+		}
+
+		entries = retry
+		k.sleep(backoff.backoff(uint64(attempt)))
+	}
+}
+
+func (k *kinesisBatchWriter[M]) sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	timer := k.clock.AfterFunc(d, func() { close(done) })
+
+	select {
+	case <-done:
+	case <-k.closing:
+		timer.Stop()
+	}
+}
@@ -0,0 +1,198 @@
+package drain_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestNewKinesisBatchSink(t *testing.T) {
+	config := drain.KinesisBatchConfig{
+		FlushInterval: time.Hour,
+		MaxAttempts:   3,
+		Backoff: drain.ExponentialBackoffConfig{
+			Base:   time.Millisecond,
+			Factor: time.Millisecond,
+			Max:    time.Millisecond,
+		},
+	}
+
+	marshaller := func(message string) ([]byte, error) {
+		if message == "unmarshallable" {
+			return nil, fmt.Errorf("cannot marshal")
+		}
+
+		return []byte(message), nil
+	}
+
+	t.Run("GIVEN a kinesis batch sink AND more messages than fit in a single request", func(t *testing.T) {
+		client := &mockKinesisBatchClient{}
+		sink, err := drain.NewKinesisBatchSink[string](gofakeit.UUID(), client, marshaller, time.Second, config, nil)
+		require.NoError(t, err)
+
+		t.Run("WHEN writing them THEN they are sent using requests within the record limit", func(t *testing.T) {
+			for i := 0; i < drain.KinesisMaxBatchRecords+10; i++ {
+				require.NoError(t, sink.Write(fmt.Sprint(i)))
+			}
+
+			require.NoError(t, sink.Close())
+			require.Equal(t, []int{drain.KinesisMaxBatchRecords, 10}, client.requestSizes())
+		})
+	})
+
+	t.Run("GIVEN a kinesis batch sink AND messages too large to be sent together", func(t *testing.T) {
+		client := &mockKinesisBatchClient{}
+		sink, err := drain.NewKinesisBatchSink[string](gofakeit.UUID(), client, marshaller, time.Second, config, nil)
+		require.NoError(t, err)
+
+		t.Run("WHEN writing them THEN they are sent using requests within the bytes limit", func(t *testing.T) {
+			large := strings.Repeat("x", drain.KinesisMaxRecordBytes-100)
+
+			for i := 0; i < 6; i++ {
+				require.NoError(t, sink.Write(large))
+			}
+
+			require.NoError(t, sink.Close())
+			require.Equal(t, []int{5, 1}, client.requestSizes())
+		})
+	})
+
+	t.Run("GIVEN a kinesis batch sink with a client rejecting some records", func(t *testing.T) {
+		client := &mockKinesisBatchClient{
+			rejections: map[string]int{"flaky": 1, "broken": 100},
+		}
+
+		var (
+			mu     sync.Mutex
+			failed []string
+		)
+
+		sink, err := drain.NewKinesisBatchSink[string](gofakeit.UUID(), client, marshaller, time.Second, config, func(message string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			failed = append(failed, message)
+		})
+		require.NoError(t, err)
+
+		t.Run("WHEN writing messages THEN only rejected records are retried AND permanent failures reach the error callback", func(t *testing.T) {
+			for _, m := range []string{"ok", "flaky", "broken", "unmarshallable"} {
+				require.NoError(t, sink.Write(m))
+			}
+
+			require.NoError(t, sink.Close())
+
+			require.Equal(t, []string{"broken", "unmarshallable"}, failed)
+			require.Equal(t, [][]string{
+				{"ok", "flaky", "broken"},
+				{"flaky", "broken"},
+				{"broken"},
+			}, client.requests())
+		})
+	})
+
+	t.Run("GIVEN a kinesis batch sink backing off for long AND a client rejecting a record once", func(t *testing.T) {
+		client := &mockKinesisBatchClient{
+			rejections: map[string]int{"flaky": 1},
+		}
+
+		slow := config
+		slow.Backoff = drain.ExponentialBackoffConfig{Base: time.Hour, Factor: time.Hour, Max: time.Hour}
+
+		sink, err := drain.NewKinesisBatchSink[string](gofakeit.UUID(), client, marshaller, time.Second, slow, nil)
+		require.NoError(t, err)
+
+		t.Run("WHEN closing the sink THEN it does not wait for the backoff AND the record is retried", func(t *testing.T) {
+			require.NoError(t, sink.Write("ok"))
+			require.NoError(t, sink.Write("flaky"))
+
+			closed := make(chan error, 1)
+
+			go func() {
+				closed <- sink.Close()
+			}()
+
+			select {
+			case cErr := <-closed:
+				require.NoError(t, cErr)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "close waited for the backoff")
+			}
+
+			require.Equal(t, [][]string{{"ok", "flaky"}, {"flaky"}}, client.requests())
+		})
+	})
+}
+
+// mockKinesisBatchClient accepts every record, except the ones whose data is
+// listed in rejections, which are rejected as many times as given.
+type mockKinesisBatchClient struct {
+	mu         sync.Mutex
+	rejections map[string]int
+	calls      [][]string
+}
+
+func (m *mockKinesisBatchClient) PutRecords(_ context.Context, params *kinesis.PutRecordsInput, _ ...func(*kinesis.Options)) (*kinesis.PutRecordsOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var (
+		call   []string
+		failed int32
+	)
+
+	out := &kinesis.PutRecordsOutput{}
+
+	for _, record := range params.Records {
+		data := string(record.Data)
+		call = append(call, data)
+
+		if m.rejections[data] > 0 {
+			m.rejections[data]--
+			failed++
+
+			out.Records = append(out.Records, types.PutRecordsResultEntry{
+				ErrorCode:    aws.String("ProvisionedThroughputExceededException"),
+				ErrorMessage: aws.String("slow down"),
+			})
+
+			continue
+		}
+
+		out.Records = append(out.Records, types.PutRecordsResultEntry{SequenceNumber: aws.String("1")})
+	}
+
+	m.calls = append(m.calls, call)
+	out.FailedRecordCount = aws.Int32(failed)
+
+	return out, nil
+}
+
+func (m *mockKinesisBatchClient) requests() [][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([][]string{}, m.calls...)
+}
+
+func (m *mockKinesisBatchClient) requestSizes() []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sizes := make([]int, len(m.calls))
+	for i := range m.calls {
+		sizes[i] = len(m.calls[i])
+	}
+
+	return sizes
+}
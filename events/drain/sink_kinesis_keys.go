@@ -0,0 +1,211 @@
+package drain
+
+import (
+	"container/list"
+
+	"github.com/tangelo-labs/go-domain"
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+// KinesisSequenceKeys is the number of partition keys whose last sequence
+// number is remembered by Kinesis sinks, see WithSequenceOrdering.
+const KinesisSequenceKeys = 10000
+
+// PartitionKeyFn computes the Kinesis partition key of a message. Records
+// sharing a partition key land in the same shard, and hence are read in the
+// same order they were put. Empty keys are replaced by random ones.
+type PartitionKeyFn[M any] func(message M) string
+
+// PartitionKeyer is implemented by messages that know their own partition
+// key, see InterfacePartitionKey.
+type PartitionKeyer interface {
+	PartitionKey() string
+}
+
+// KinesisOption configures the Kinesis sinks writing messages of type M.
+type KinesisOption[M any] func(*kinesisOptions[M])
+
+type kinesisOptions[M any] struct {
+	sink             []SinkOption
+	keys             kinesisKeys[M]
+	sequenceOrdering bool
+}
+
+// WithSinkOptions applies the given options, common to every sink, such as
+// WithName or WithObserver, to Kinesis sinks.
+func WithSinkOptions[M any](opts ...SinkOption) KinesisOption[M] {
+	return func(o *kinesisOptions[M]) {
+		o.sink = append(o.sink, opts...)
+	}
+}
+
+// WithPartitionKey sets the function computing the partition key of each
+// message written to Kinesis sinks, so related messages, such as the events
+// of a same aggregate, keep their order. Defaults to random keys, which
+// spread messages evenly across shards.
+//
+// Batch sinks only keep such order as long as records are not retried: a
+// record rejected by Kinesis is retried after the records following it in its
+// batch were put, even if they share its partition key. Use NewKinesisSink
+// along with WithSequenceOrdering when strict per-key order is required.
+func WithPartitionKey[M any](fn PartitionKeyFn[M]) KinesisOption[M] {
+	return func(o *kinesisOptions[M]) {
+		o.keys.partition = fn
+	}
+}
+
+// WithExplicitHashKey sets the function computing the hash key of each
+// message written to Kinesis sinks, overriding the hash of its partition key
+// when choosing its shard. Empty hash keys are ignored.
+func WithExplicitHashKey[M any](fn func(message M) string) KinesisOption[M] {
+	return func(o *kinesisOptions[M]) {
+		o.keys.hash = fn
+	}
+}
+
+// WithSequenceOrdering makes Kinesis sinks chain the records of each
+// partition key, by giving every record the sequence number of the previous
+// one put with the same key, see SequenceNumberForOrdering in the Kinesis
+// PutRecord API. This guarantees strict ordering even when a write is retried.
+//
+// Writes of such records are serialized, and the last sequence number of the
+// most recently written KinesisSequenceKeys partition keys is kept in memory. Keys not
+// written for a while are forgotten, so the next record put with such a key is
+// not chained to the previous one. As writes are serialized, such record still
+// lands after the previous one, unless the latter is being retried. Records
+// given random keys, see WithPartitionKey, are never chained, as no other
+// record shares their key. Not supported by batch sinks, as PutRecords
+// requests cannot be chained.
+func WithSequenceOrdering[M any]() KinesisOption[M] {
+	return func(o *kinesisOptions[M]) {
+		o.sequenceOrdering = true
+	}
+}
+
+func newKinesisOptions[M any](opts ...KinesisOption[M]) kinesisOptions[M] {
+	var o kinesisOptions[M]
+
+	for i := range opts {
+		opts[i](&o)
+	}
+
+	return o
+}
+
+// AggregateIDPartitionKey keys envelopes by the ID of the aggregate that
+// emitted them, so events of a same aggregate stay in order. Other messages
+// get random keys.
+func AggregateIDPartitionKey[M any](message M) string {
+	if env, ok := any(message).(events.Envelope); ok {
+		return env.AggregateID
+	}
+
+	return ""
+}
+
+// EnvelopePartitionKey keys envelopes by the given field of them, such as
+// their correlation ID. Other messages get random keys.
+func EnvelopePartitionKey[M any](field func(env events.Envelope) string) PartitionKeyFn[M] {
+	return func(message M) string {
+		if env, ok := any(message).(events.Envelope); ok {
+			return field(env)
+		}
+
+		return ""
+	}
+}
+
+// InterfacePartitionKey keys messages implementing PartitionKeyer by their
+// own key. Envelopes are keyed by their payload. Other messages get random
+// keys.
+func InterfacePartitionKey[M any](message M) string {
+	if k, ok := events.Unwrap(any(message)).(PartitionKeyer); ok {
+		return k.PartitionKey()
+	}
+
+	return ""
+}
+
+// kinesisKeys computes the keys of the records put into Kinesis streams.
+type kinesisKeys[M any] struct {
+	partition PartitionKeyFn[M]
+	hash      func(M) string
+}
+
+// partitionKey returns the partition key of the given message, a random one if
+// none. Tells whether the key was computed from the message, rather than being
+// random.
+func (k kinesisKeys[M]) partitionKey(message M) (string, bool) {
+	if k.partition != nil {
+		if key := k.partition(message); key != "" {
+			return key, true
+		}
+	}
+
+	return domain.NewID().String(), false
+}
+
+// hashKey returns the explicit hash key of the given message, nil if none.
+func (k kinesisKeys[M]) hashKey(message M) *string {
+	if k.hash == nil {
+		return nil
+	}
+
+	if key := k.hash(message); key != "" {
+		return &key
+	}
+
+	return nil
+}
+
+// sequenceCache holds the last sequence number of a bounded number of
+// partition keys, evicting the least recently used key when full. It is not
+// safe for concurrent use.
+type sequenceCache struct {
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type sequenceEntry struct {
+	key      string
+	sequence string
+}
+
+func newSequenceCache(capacity int) *sequenceCache {
+	return &sequenceCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns the last sequence number of the given partition key, if known.
+func (c *sequenceCache) get(key string) (string, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return elem.Value.(*sequenceEntry).sequence, true
+}
+
+// set records the last sequence number of the given partition key.
+func (c *sequenceCache) set(key, sequence string) {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*sequenceEntry).sequence = sequence
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&sequenceEntry{key: key, sequence: sequence})
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*sequenceEntry).key)
+	}
+}
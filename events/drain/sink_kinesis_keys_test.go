@@ -0,0 +1,213 @@
+package drain_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+)
+
+func TestKinesisPartitionKeys(t *testing.T) {
+	ctx := context.Background()
+	envelope := func(aggregateID string, payload events.Event) events.Event {
+		env := events.Wrap(ctx, payload)
+		env.AggregateID = aggregateID
+		env.CorrelationID = "corr-" + aggregateID
+
+		return env
+	}
+
+	t.Run("GIVEN built-in partition key functions", func(t *testing.T) {
+		t.Run("WHEN keying by aggregate ID THEN envelopes use their aggregate ID AND other messages have no key", func(t *testing.T) {
+			require.Equal(t, "order-1", drain.AggregateIDPartitionKey[events.Event](envelope("order-1", "x")))
+			require.Empty(t, drain.AggregateIDPartitionKey[events.Event]("x"))
+		})
+
+		t.Run("WHEN keying by an envelope field THEN envelopes use that field", func(t *testing.T) {
+			fn := drain.EnvelopePartitionKey[events.Event](func(env events.Envelope) string {
+				return env.CorrelationID
+			})
+
+			require.Equal(t, "corr-order-1", fn(envelope("order-1", "x")))
+			require.Empty(t, fn("x"))
+		})
+
+		t.Run("WHEN keying by interface THEN messages and envelope payloads implementing it use their own key", func(t *testing.T) {
+			require.Equal(t, "k1", drain.InterfacePartitionKey[events.Event](keyedMessage{Key: "k1"}))
+			require.Equal(t, "k2", drain.InterfacePartitionKey[events.Event](envelope("order-1", keyedMessage{Key: "k2"})))
+			require.Empty(t, drain.InterfacePartitionKey[events.Event]("x"))
+		})
+	})
+
+	t.Run("GIVEN a kinesis sink keyed by aggregate ID with explicit hash keys AND sequence ordering", func(t *testing.T) {
+		client := &recordingKinesisClient{}
+		sink, err := drain.NewKinesisSink[events.Event](gofakeit.UUID(), client, drain.JSONMarshaller, time.Second, nil,
+			drain.WithPartitionKey(drain.AggregateIDPartitionKey[events.Event]),
+			drain.WithExplicitHashKey(func(events.Event) string { return "42" }),
+			drain.WithSequenceOrdering[events.Event](),
+		)
+		require.NoError(t, err)
+
+		t.Run("WHEN writing events of two aggregates THEN records are keyed by aggregate AND chained per key", func(t *testing.T) {
+			require.NoError(t, sink.Write(envelope("a", "1")))
+			require.NoError(t, sink.Write(envelope("b", "2")))
+			require.NoError(t, sink.Write(envelope("a", "3")))
+
+			inputs := client.putRecordInputs()
+			require.Len(t, inputs, 3)
+
+			require.Equal(t, []string{"a", "b", "a"}, []string{
+				aws.ToString(inputs[0].PartitionKey),
+				aws.ToString(inputs[1].PartitionKey),
+				aws.ToString(inputs[2].PartitionKey),
+			})
+
+			require.Equal(t, "42", aws.ToString(inputs[0].ExplicitHashKey))
+			require.Nil(t, inputs[0].SequenceNumberForOrdering)
+			require.Nil(t, inputs[1].SequenceNumberForOrdering)
+			require.Equal(t, "seq-1", aws.ToString(inputs[2].SequenceNumberForOrdering))
+		})
+
+		t.Run("WHEN writing a message without aggregate THEN a random key is used", func(t *testing.T) {
+			require.NoError(t, sink.Write("plain"))
+
+			inputs := client.putRecordInputs()
+			require.NotEmpty(t, aws.ToString(inputs[3].PartitionKey))
+		})
+	})
+
+	t.Run("GIVEN a kinesis sink with sequence ordering", func(t *testing.T) {
+		client := &recordingKinesisClient{}
+		sink, err := drain.NewKinesisSink[events.Event](gofakeit.UUID(), client, drain.JSONMarshaller, time.Second, nil,
+			drain.WithPartitionKey(drain.AggregateIDPartitionKey[events.Event]),
+			drain.WithSequenceOrdering[events.Event](),
+		)
+		require.NoError(t, err)
+
+		t.Run("WHEN writing more keys than remembered THEN the least recently used key is forgotten", func(t *testing.T) {
+			require.NoError(t, sink.Write(envelope("first", "x")))
+			require.NoError(t, sink.Write(envelope("second", "x")))
+
+			for i := 0; i < drain.KinesisSequenceKeys-2; i++ {
+				require.NoError(t, sink.Write(envelope(fmt.Sprintf("key-%d", i), "x")))
+			}
+
+			// Touching "first" makes "second" the least recently used key.
+			require.NoError(t, sink.Write(envelope("first", "x")))
+			require.NoError(t, sink.Write(envelope("overflow", "x")))
+			require.NoError(t, sink.Write(envelope("second", "x")))
+			require.NoError(t, sink.Write(envelope("first", "x")))
+
+			inputs := client.putRecordInputs()
+			n := len(inputs)
+
+			require.NotNil(t, inputs[n-4].SequenceNumberForOrdering)
+			require.Nil(t, inputs[n-2].SequenceNumberForOrdering)
+			require.NotNil(t, inputs[n-1].SequenceNumberForOrdering)
+		})
+
+		t.Run("WHEN writing more messages with random keys than remembered THEN they are not chained AND keyed records stay chained", func(t *testing.T) {
+			require.NoError(t, sink.Write(envelope("kept", "x")))
+
+			for i := 0; i < drain.KinesisSequenceKeys; i++ {
+				require.NoError(t, sink.Write("plain"))
+			}
+
+			require.NoError(t, sink.Write(envelope("kept", "x")))
+
+			inputs := client.putRecordInputs()
+			n := len(inputs)
+
+			require.Nil(t, inputs[n-2].SequenceNumberForOrdering)
+			require.NotNil(t, inputs[n-1].SequenceNumberForOrdering)
+		})
+	})
+
+	t.Run("GIVEN a kinesis batch sink keyed by interface", func(t *testing.T) {
+		client := &recordingKinesisClient{}
+		sink, err := drain.NewKinesisBatchSink[events.Event](gofakeit.UUID(), client, drain.JSONMarshaller, time.Second, drain.KinesisBatchConfig{}, nil,
+			drain.WithPartitionKey(drain.InterfacePartitionKey[events.Event]),
+		)
+		require.NoError(t, err)
+
+		t.Run("WHEN writing messages THEN records are keyed accordingly", func(t *testing.T) {
+			require.NoError(t, sink.Write(keyedMessage{Key: "k1"}))
+			require.NoError(t, sink.Write(keyedMessage{Key: "k2"}))
+			require.NoError(t, sink.Close())
+
+			records := client.putRecordsEntries()
+			require.Len(t, records, 2)
+			require.Equal(t, "k1", aws.ToString(records[0].PartitionKey))
+			require.Equal(t, "k2", aws.ToString(records[1].PartitionKey))
+		})
+	})
+
+	t.Run("GIVEN sequence ordering WHEN building a kinesis batch sink THEN it fails", func(t *testing.T) {
+		_, err := drain.NewKinesisBatchSink[events.Event](gofakeit.UUID(), &recordingKinesisClient{}, drain.JSONMarshaller, time.Second, drain.KinesisBatchConfig{}, nil,
+			drain.WithSequenceOrdering[events.Event](),
+		)
+		require.Error(t, err)
+	})
+}
+
+type keyedMessage struct {
+	Key string
+}
+
+func (k keyedMessage) PartitionKey() string {
+	return k.Key
+}
+
+// recordingKinesisClient accepts every record, recording the inputs of every
+// request and assigning increasing sequence numbers.
+type recordingKinesisClient struct {
+	mu      sync.Mutex
+	seq     int
+	records []*kinesis.PutRecordInput
+	batches []types.PutRecordsRequestEntry
+}
+
+func (r *recordingKinesisClient) PutRecord(_ context.Context, params *kinesis.PutRecordInput, _ ...func(*kinesis.Options)) (*kinesis.PutRecordOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	r.records = append(r.records, params)
+
+	return &kinesis.PutRecordOutput{SequenceNumber: aws.String(fmt.Sprintf("seq-%d", r.seq))}, nil
+}
+
+func (r *recordingKinesisClient) PutRecords(_ context.Context, params *kinesis.PutRecordsInput, _ ...func(*kinesis.Options)) (*kinesis.PutRecordsOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches = append(r.batches, params.Records...)
+
+	return &kinesis.PutRecordsOutput{
+		FailedRecordCount: aws.Int32(0),
+		Records:           make([]types.PutRecordsResultEntry, len(params.Records)),
+	}, nil
+}
+
+func (r *recordingKinesisClient) putRecordInputs() []*kinesis.PutRecordInput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*kinesis.PutRecordInput{}, r.records...)
+}
+
+func (r *recordingKinesisClient) putRecordsEntries() []types.PutRecordsRequestEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]types.PutRecordsRequestEntry{}, r.batches...)
+}
@@ -0,0 +1,72 @@
+package drain
+
+import (
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/observe"
+)
+
+// Components reported to observers, see observe.Labels.
+const (
+	componentQueue       = "queue"
+	componentRetrying    = "retrying"
+	componentBroadcaster = "broadcaster"
+	componentKinesis     = "kinesis"
+	componentBatcher     = "batcher"
+)
+
+// SinkOption configures the sinks built by this package.
+type SinkOption func(*sinkOptions)
+
+type sinkOptions struct {
+	name     string
+	observer observe.Observer
+	observed bool
+	clock    Clock
+}
+
+// WithName names the sink, so observations of different sinks of the same
+// kind can be told apart, see observe.Labels.
+func WithName(name string) SinkOption {
+	return func(o *sinkOptions) {
+		o.name = name
+	}
+}
+
+// WithObserver sets the observer notified about messages flowing through the
+// sink. See observe.Metrics for exporting such observations as metrics.
+func WithObserver(obs observe.Observer) SinkOption {
+	return func(o *sinkOptions) {
+		o.observer = obs
+	}
+}
+
+func newSinkOptions(name string, opts ...SinkOption) sinkOptions {
+	o := sinkOptions{
+		name:     name,
+		observer: observe.Nop{},
+		clock:    realClock{},
+	}
+
+	for i := range opts {
+		opts[i](&o)
+	}
+
+	_, nop := o.observer.(observe.Nop)
+	o.observed = !nop
+
+	return o
+}
+
+// labels returns the labels of observations of the given message. Labels are
+// only built for observers other than observe.Nop, as building them allocates.
+func (o sinkOptions) labels(component string, message interface{}) observe.Labels {
+	if !o.observed {
+		return observe.Labels{}
+	}
+
+	return observe.Labels{
+		Component: component,
+		Name:      o.name,
+		EventType: events.TypeName(message),
+	}
+}
@@ -0,0 +1,97 @@
+package drain_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+	"github.com/tangelo-labs/go-domain/events/drain"
+	"github.com/tangelo-labs/go-domain/events/observe"
+)
+
+func TestSinkObserver(t *testing.T) {
+	labels := func(component, name string) observe.Labels {
+		return observe.Labels{Component: component, Name: name, EventType: "string"}
+	}
+
+	t.Run("GIVEN an observed queue over a failing sink WHEN writing messages THEN failures, drops and queue depth are observed", func(t *testing.T) {
+		metrics := observe.NewMetrics()
+		queue := drain.NewQueue[events.Event](&dropperSink[events.Event]{err: errors.New("failed")}, 1, nil,
+			drain.WithName("audit"),
+			drain.WithObserver(metrics),
+		)
+
+		require.NoError(t, queue.Write("a"))
+		require.NoError(t, queue.Write("b"))
+		require.NoError(t, queue.Close())
+
+		require.EqualValues(t, 2, metrics.Value(observe.MetricDispatched, labels("queue", "audit")))
+		require.EqualValues(t, 2, metrics.Value(observe.MetricFailed, labels("queue", "audit")))
+		require.EqualValues(t, 2, metrics.Value(observe.MetricDropped, labels("queue", "audit")))
+		require.EqualValues(t, 0, metrics.Value(observe.MetricQueueDepth, observe.Labels{Component: "queue", Name: "audit"}))
+	})
+
+	t.Run("GIVEN an observed retrying sink over a sink failing once WHEN writing a message THEN the retry and delivery are observed", func(t *testing.T) {
+		metrics := observe.NewMetrics()
+		dst := &failingOnceSink{Sink: newTestSink[events.Event](t, 1)}
+		retrying := drain.NewRetrying[events.Event](dst, drain.NewBreakerStrategy[events.Event](10, time.Millisecond), nil,
+			drain.WithObserver(metrics),
+		)
+
+		require.NoError(t, retrying.Write("a"))
+		require.NoError(t, retrying.Close())
+
+		require.EqualValues(t, 1, metrics.Value(observe.MetricDispatched, labels("retrying", "")))
+		require.EqualValues(t, 1, metrics.Value(observe.MetricRetried, labels("retrying", "")))
+		require.EqualValues(t, 1, metrics.Value(observe.MetricDelivered, labels("retrying", "")))
+	})
+
+	t.Run("GIVEN an observed broadcaster over two sinks WHEN writing a message THEN a delivery per sink is observed", func(t *testing.T) {
+		metrics := observe.NewMetrics()
+		bc := drain.NewBroadcasterWithOptions[events.Event](nil, []drain.Sink[events.Event]{
+			newTestSink[events.Event](t, 1),
+			newTestSink[events.Event](t, 1),
+		}, drain.WithObserver(metrics))
+
+		require.NoError(t, bc.Write("a"))
+		require.Eventually(t, func() bool {
+			return metrics.Value(observe.MetricDelivered, labels("broadcaster", "")) == 2
+		}, time.Second, time.Millisecond)
+		require.NoError(t, bc.Close())
+
+		require.EqualValues(t, 1, metrics.Value(observe.MetricDispatched, labels("broadcaster", "")))
+	})
+
+	t.Run("GIVEN an observed kinesis sink with a failing client WHEN writing a message THEN the failure is observed under the stream name", func(t *testing.T) {
+		metrics := observe.NewMetrics()
+		stream := gofakeit.UUID()
+		sink, err := drain.NewKinesisSink[events.Event](stream, &mockKinesisClient{err: fmt.Errorf("some error")}, drain.JSONMarshaller, time.Second, nil,
+			drain.WithSinkOptions[events.Event](drain.WithObserver(metrics)),
+		)
+		require.NoError(t, err)
+
+		require.Error(t, sink.Write("a"))
+		require.EqualValues(t, 1, metrics.Value(observe.MetricDispatched, labels("kinesis", stream)))
+		require.EqualValues(t, 1, metrics.Value(observe.MetricFailed, labels("kinesis", stream)))
+	})
+}
+
+// failingOnceSink fails the first write, and forwards any other.
+type failingOnceSink struct {
+	drain.Sink[events.Event]
+	failed bool
+}
+
+func (f *failingOnceSink) Write(message events.Event) error {
+	if !f.failed {
+		f.failed = true
+
+		return errors.New("failed once")
+	}
+
+	return f.Sink.Write(message)
+}
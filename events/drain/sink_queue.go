@@ -4,6 +4,9 @@ import (
 	"container/list"
 	"fmt"
 	"sync"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events/observe"
 )
 
 // queue accepts all messages into a queue for asynchronous consumption
@@ -18,6 +21,7 @@ type queueSink[M any] struct {
 	wg           sync.WaitGroup
 	closing      bool
 	dropHandling WriteErrorFn[M]
+	opts         sinkOptions
 }
 
 type queueEnvelope[M any] struct {
@@ -27,7 +31,7 @@ type queueEnvelope[M any] struct {
 
 // NewQueue returns a queue Sink with a given throughput to the provided Sink dst.
 // nil dropHandling will set a noop handler.
-func NewQueue[M any](dst Sink[M], throughput int, dropHandling WriteErrorFn[M]) Sink[M] {
+func NewQueue[M any](dst Sink[M], throughput int, dropHandling WriteErrorFn[M], opts ...SinkOption) Sink[M] {
 	dh := dropHandling
 	if dh == nil {
 		dh = noopWriteError[M]
@@ -38,6 +42,7 @@ func NewQueue[M any](dst Sink[M], throughput int, dropHandling WriteErrorFn[M])
 		dst:          dst,
 		list:         list.New(),
 		dropHandling: dh,
+		opts:         newSinkOptions("", opts...),
 	}
 
 	if throughput <= 0 {
@@ -67,6 +72,9 @@ func (eq *queueSink[M]) Write(m M) error {
 	eq.list.PushBack(queueEnvelope[M]{message: m})
 	eq.cond.Signal() // signal waiters
 
+	eq.opts.observer.Dispatched(eq.opts.labels(componentQueue, m))
+	eq.opts.observer.QueueDepth(eq.depthLabels(), eq.list.Len())
+
 	return nil
 }
 
@@ -110,12 +118,25 @@ func (eq *queueSink[M]) run() {
 			return // queueClosed block means event queue is closed.
 		}
 
+		labels := eq.opts.labels(componentQueue, envelope.message)
+		start := time.Now()
+
 		if err := eq.dst.Write(envelope.message); err != nil {
+			eq.opts.observer.Failed(labels, err)
+			eq.opts.observer.Dropped(labels, err)
 			eq.dropHandling(envelope.message, err)
+
+			continue
 		}
+
+		eq.opts.observer.Delivered(labels, time.Since(start))
 	}
 }
 
+func (eq *queueSink[M]) depthLabels() observe.Labels {
+	return observe.Labels{Component: componentQueue, Name: eq.opts.name}
+}
+
 // next encompasses the critical section of the run loop. When the queue is
 // empty, it will block on the condition. If new data arrives, it will wake
 // and return a block. When closed, queueClosed constant will be returned.
@@ -141,6 +162,7 @@ func (eq *queueSink[M]) next() queueEnvelope[M] {
 	}
 
 	eq.list.Remove(front)
+	eq.opts.observer.QueueDepth(eq.depthLabels(), eq.list.Len())
 
 	return block
 }
@@ -19,12 +19,13 @@ type retryingSink[M any] struct {
 	sink         Sink[M]
 	strategy     RetrySinkStrategy[M]
 	dropHandling WriteErrorFn[M]
+	opts         sinkOptions
 }
 
 // NewRetrying returns a sink that will retry writes to a sink, backing
 // off on failure. Parameters threshold and backoff adjust the behavior of the
 // circuit breaker.
-func NewRetrying[M any](sink Sink[M], strategy RetrySinkStrategy[M], dropHandling WriteErrorFn[M]) Sink[M] {
+func NewRetrying[M any](sink Sink[M], strategy RetrySinkStrategy[M], dropHandling WriteErrorFn[M], opts ...SinkOption) Sink[M] {
 	dh := dropHandling
 	if dh == nil {
 		dh = noopWriteError[M]
@@ -35,6 +36,7 @@ func NewRetrying[M any](sink Sink[M], strategy RetrySinkStrategy[M], dropHandlin
 		sink:         sink,
 		strategy:     strategy,
 		dropHandling: dh,
+		opts:         newSinkOptions("", opts...),
 	}
 
 	return rs
@@ -43,7 +45,17 @@ func NewRetrying[M any](sink Sink[M], strategy RetrySinkStrategy[M], dropHandlin
 // Write attempts to flush the messages to the downstream sink until it succeeds
 // or the sink is closed.
 func (rs *retryingSink[M]) Write(message M) error {
+	labels := rs.opts.labels(componentRetrying, message)
+	rs.opts.observer.Dispatched(labels)
+
+	start := time.Now()
+	attempt := 1
+
 retry:
+	if attempt > 1 {
+		rs.opts.observer.Retried(labels, attempt)
+	}
+
 	if rs.baseSink.IsClosed() {
 		return fmt.Errorf("%w: retrying sink could not write message %T", ErrSinkClosed, message)
 	}
@@ -64,19 +76,26 @@ retry:
 	if err := rs.sink.Write(message); err != nil {
 		if errors.Is(err, ErrSinkClosed) {
 			// terminal!
+			rs.opts.observer.Failed(labels, err)
+
 			return err
 		}
 
 		if rs.strategy.Failure(message, err) {
+			rs.opts.observer.Failed(labels, err)
+			rs.opts.observer.Dropped(labels, err)
 			rs.dropHandling(message, err)
 
 			return nil
 		}
 
+		attempt++
+
 		goto retry
 	}
 
 	rs.strategy.Success(message)
+	rs.opts.observer.Delivered(labels, time.Since(start))
 
 	return nil
 }
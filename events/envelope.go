@@ -0,0 +1,213 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"reflect"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// Envelope wraps a domain event with standard metadata describing when it
+// happened, which aggregate emitted it and which request caused it.
+//
+// Envelopes are always handled by value. Consumers not interested in the
+// metadata may use Unwrap to get the original event.
+type Envelope struct {
+	// EventID uniquely identifies the event.
+	EventID string `json:"eventId"`
+
+	// EventType is a stable name of the event type, see TypeName.
+	EventType string `json:"eventType"`
+
+	// OccurredAt is the moment the event was recorded, in UTC.
+	OccurredAt time.Time `json:"occurredAt"`
+
+	// AggregateID identifies the aggregate that emitted the event, if any.
+	AggregateID string `json:"aggregateId,omitempty"`
+
+	// AggregateType describes the kind of aggregate that emitted the event,
+	// such as "order".
+	AggregateType string `json:"aggregateType,omitempty"`
+
+	// AggregateVersion is the version of the aggregate right after the event
+	// was recorded.
+	AggregateVersion int `json:"aggregateVersion,omitempty"`
+
+	// CorrelationID identifies the request or workflow the event belongs to.
+	// Every event caused, directly or indirectly, by the same request shares
+	// the same correlation ID.
+	CorrelationID string `json:"correlationId,omitempty"`
+
+	// CausationID identifies the message, usually another event or command,
+	// that directly caused this event.
+	CausationID string `json:"causationId,omitempty"`
+
+	// Metadata holds free-form key-value pairs.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Payload is the wrapped domain event.
+	Payload Event `json:"payload"`
+}
+
+// idGenerator generates unique event IDs.
+var idGenerator = func() string {
+	return ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+}
+
+// SetIDGenerator sets the function used to generate event IDs. Call this
+// function before recording any event, preferably in an init() function.
+//
+// By default, the ULID algorithm is used.
+func SetIDGenerator(fn func() string) {
+	idGenerator = fn
+}
+
+// Wrap builds a new envelope for the given event, stamping a new event ID, the
+// current time, and the correlation data found in the given context. If the
+// event is already an envelope, it is returned as is.
+func Wrap(ctx context.Context, event Event) Envelope {
+	if env, ok := event.(Envelope); ok {
+		return env
+	}
+
+	env := Envelope{
+		EventID:    idGenerator(),
+		EventType:  TypeName(event),
+		OccurredAt: time.Now().UTC(),
+		Payload:    event,
+	}
+
+	if c, ok := correlationFromContext(ctx); ok {
+		env.CorrelationID = c.correlationID
+		env.CausationID = c.causationID
+
+		if len(c.metadata) > 0 {
+			env.Metadata = make(map[string]string, len(c.metadata))
+			for k, v := range c.metadata {
+				env.Metadata[k] = v
+			}
+		}
+	}
+
+	return env
+}
+
+// Unwrap returns the payload of the given event if it is an envelope,
+// otherwise returns the event itself.
+func Unwrap(event Event) Event {
+	if env, ok := event.(Envelope); ok {
+		return env.Payload
+	}
+
+	return event
+}
+
+// Namer defines an event capable of describing its own stable name. Event names
+// should not change across refactors, as they are usually persisted or sent to
+// other systems.
+type Namer interface {
+	// EventName returns the stable name of the event, e.g. "order.created".
+	EventName() string
+}
+
+// TypeName returns the name of the given event. That is, the result of its
+// EventName method if the event implements the Namer interface, otherwise its
+// Go type name as described by the reflect package, e.g. "order.CreatedEvent".
+// Envelopes are described by the name of their payload.
+func TypeName(event Event) string {
+	event = Unwrap(event)
+
+	if n, ok := event.(Namer); ok {
+		return n.EventName()
+	}
+
+	if event == nil {
+		return ""
+	}
+
+	return reflect.TypeOf(event).String()
+}
+
+type correlationKey struct{}
+
+type correlation struct {
+	correlationID string
+	causationID   string
+	metadata      map[string]string
+}
+
+// WithCorrelation returns a copy of the given context carrying the given
+// correlation and causation IDs. Events wrapped using such context will be
+// stamped with these values.
+func WithCorrelation(ctx context.Context, correlationID, causationID string) context.Context {
+	c, _ := correlationFromContext(ctx)
+	c.correlationID = correlationID
+	c.causationID = causationID
+
+	return context.WithValue(ctx, correlationKey{}, c)
+}
+
+// WithMetadata returns a copy of the given context carrying the given metadata
+// pair, in addition to any metadata already present in the context. Events
+// wrapped using such context will include this metadata.
+func WithMetadata(ctx context.Context, key, value string) context.Context {
+	c, _ := correlationFromContext(ctx)
+	md := make(map[string]string, len(c.metadata)+1)
+
+	for k, v := range c.metadata {
+		md[k] = v
+	}
+
+	md[key] = value
+	c.metadata = md
+
+	return context.WithValue(ctx, correlationKey{}, c)
+}
+
+// CorrelationFromContext returns the correlation and causation IDs carried by
+// the given context, if any.
+func CorrelationFromContext(ctx context.Context) (correlationID, causationID string) {
+	c, _ := correlationFromContext(ctx)
+
+	return c.correlationID, c.causationID
+}
+
+type envelopeKey struct{}
+
+// ContextWithEnvelope returns a copy of the given context carrying the given
+// envelope, so handlers receiving the envelope payload can still access its
+// metadata using EnvelopeFromContext.
+//
+// The returned context is also correlated to the envelope: events wrapped using
+// it share the correlation ID of the envelope, or its event ID if it has none,
+// and are caused by the envelope event.
+func ContextWithEnvelope(ctx context.Context, env Envelope) context.Context {
+	correlationID := env.CorrelationID
+	if correlationID == "" {
+		correlationID = env.EventID
+	}
+
+	ctx = WithCorrelation(ctx, correlationID, env.EventID)
+
+	return context.WithValue(ctx, envelopeKey{}, env)
+}
+
+// EnvelopeFromContext returns the envelope carried by the given context, if
+// any.
+func EnvelopeFromContext(ctx context.Context) (Envelope, bool) {
+	env, ok := ctx.Value(envelopeKey{}).(Envelope)
+
+	return env, ok
+}
+
+func correlationFromContext(ctx context.Context) (correlation, bool) {
+	if ctx == nil {
+		return correlation{}, false
+	}
+
+	c, ok := ctx.Value(correlationKey{}).(correlation)
+
+	return c, ok
+}
@@ -0,0 +1,93 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+func TestEnvelope(t *testing.T) {
+	t.Run("GIVEN a context carrying correlation data", func(t *testing.T) {
+		correlationID := gofakeit.UUID()
+		causationID := gofakeit.UUID()
+
+		ctx := events.WithCorrelation(context.Background(), correlationID, causationID)
+		ctx = events.WithMetadata(ctx, "tenant", "acme")
+
+		t.Run("WHEN wrapping an event THEN envelope is stamped with such data", func(t *testing.T) {
+			env := events.Wrap(ctx, userRegistered{Name: gofakeit.Name()})
+
+			require.NotEmpty(t, env.EventID)
+			require.Equal(t, "events_test.userRegistered", env.EventType)
+			require.False(t, env.OccurredAt.IsZero())
+			require.Equal(t, correlationID, env.CorrelationID)
+			require.Equal(t, causationID, env.CausationID)
+			require.Equal(t, map[string]string{"tenant": "acme"}, env.Metadata)
+		})
+
+		t.Run("WHEN wrapping an envelope THEN it is returned untouched", func(t *testing.T) {
+			env := events.Wrap(ctx, userRegistered{})
+
+			require.Equal(t, env, events.Wrap(context.Background(), env))
+		})
+	})
+
+	t.Run("GIVEN an event implementing the Namer interface WHEN wrapping it THEN its event name is used as type", func(t *testing.T) {
+		env := events.Wrap(context.Background(), namedEvent{})
+
+		require.Equal(t, "user.named", env.EventType)
+		require.Equal(t, namedEvent{}, events.Unwrap(env))
+	})
+
+	t.Run("GIVEN an envelope WHEN building a context from it THEN subsequent events are caused by it", func(t *testing.T) {
+		parent := events.Wrap(context.Background(), userRegistered{})
+		ctx := events.ContextWithEnvelope(context.Background(), parent)
+
+		got, ok := events.EnvelopeFromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, parent, got)
+
+		child := events.Wrap(ctx, namedEvent{})
+		require.Equal(t, parent.EventID, child.CorrelationID)
+		require.Equal(t, parent.EventID, child.CausationID)
+	})
+
+	t.Run("GIVEN a recorder bound to an aggregate", func(t *testing.T) {
+		aggregateID := gofakeit.UUID()
+		recorder := &events.BaseRecorder{}
+		recorder.SetAggregate(aggregateID, "user", 3)
+
+		t.Run("WHEN recording events THEN envelopes are stamped with aggregate data AND changes are plain payloads", func(t *testing.T) {
+			ctx := events.WithCorrelation(context.Background(), "corr", "cause")
+
+			recorder.Record(userRegistered{Name: "a"})
+			recorder.RecordContext(ctx, namedEvent{})
+
+			envelopes := recorder.Envelopes()
+			require.Len(t, envelopes, 2)
+
+			require.Equal(t, aggregateID, envelopes[0].AggregateID)
+			require.Equal(t, "user", envelopes[0].AggregateType)
+			require.Equal(t, 4, envelopes[0].AggregateVersion)
+			require.Empty(t, envelopes[0].CorrelationID)
+
+			require.Equal(t, 5, envelopes[1].AggregateVersion)
+			require.Equal(t, "corr", envelopes[1].CorrelationID)
+
+			require.Equal(t, []events.Event{userRegistered{Name: "a"}, namedEvent{}}, recorder.Changes())
+		})
+	})
+}
+
+type userRegistered struct {
+	Name string
+}
+
+type namedEvent struct{}
+
+func (namedEvent) EventName() string {
+	return "user.named"
+}
@@ -0,0 +1,23 @@
+package observe
+
+import "expvar"
+
+// Expvar returns an expvar.Var exposing every collected metric as a JSON
+// object, keyed by metric name and then by labels, formatted as Prometheus
+// does. Publish it with expvar.Publish to serve it along with other
+// variables at /debug/vars.
+func (m *Metrics) Expvar() expvar.Var {
+	return expvar.Func(func() interface{} {
+		out := make(map[string]map[string]float64)
+
+		for _, s := range m.Snapshot() {
+			if out[s.Name] == nil {
+				out[s.Name] = make(map[string]float64)
+			}
+
+			out[s.Name][formatLabels(s.Labels)] = s.Value
+		}
+
+		return out
+	})
+}
@@ -0,0 +1,141 @@
+package observe
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Names of the metrics collected by Metrics.
+const (
+	MetricDispatched       = "events_dispatched_total"
+	MetricDelivered        = "events_delivered_total"
+	MetricDeliveryDuration = "events_delivery_duration_seconds"
+	MetricFailed           = "events_failed_total"
+	MetricDropped          = "events_dropped_total"
+	MetricRetried          = "events_retried_total"
+	MetricQueueDepth       = "events_queue_depth"
+)
+
+// family describes a metric as exposed by exporters.
+type family struct {
+	name string
+	kind string
+	help string
+}
+
+var families = []family{
+	{name: MetricDispatched, kind: "counter", help: "Events accepted by a component."},
+	{name: MetricDelivered, kind: "counter", help: "Events handed over to a handler or downstream sink."},
+	{name: MetricDeliveryDuration, kind: "summary", help: "Time taken to hand over events, in seconds."},
+	{name: MetricFailed, kind: "counter", help: "Failed attempts of handing over events."},
+	{name: MetricDropped, kind: "counter", help: "Events given up on."},
+	{name: MetricRetried, kind: "counter", help: "Retried attempts of handing over events."},
+	{name: MetricQueueDepth, kind: "gauge", help: "Events waiting in the queue of a component."},
+}
+
+// Sample is the value of a metric for a set of labels.
+type Sample struct {
+	// Name is the name of the metric, suffixed with "_sum" or "_count" for
+	// summaries.
+	Name string
+
+	// Labels identifies the series of the metric.
+	Labels Labels
+
+	// Value is the current value of the series.
+	Value float64
+}
+
+type seriesKey struct {
+	name   string
+	labels Labels
+}
+
+// Metrics is an Observer that aggregates observations into counters, gauges
+// and summaries, which can be exported with Expvar or WritePrometheus.
+type Metrics struct {
+	mu     sync.Mutex
+	series map[seriesKey]float64
+}
+
+// NewMetrics builds an empty metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{series: make(map[seriesKey]float64)}
+}
+
+// Dispatched implements Observer.
+func (m *Metrics) Dispatched(labels Labels) {
+	m.add(MetricDispatched, labels, 1)
+}
+
+// Delivered implements Observer.
+func (m *Metrics) Delivered(labels Labels, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.series[seriesKey{name: MetricDelivered, labels: labels}]++
+	m.series[seriesKey{name: MetricDeliveryDuration + "_sum", labels: labels}] += elapsed.Seconds()
+	m.series[seriesKey{name: MetricDeliveryDuration + "_count", labels: labels}]++
+}
+
+// Failed implements Observer.
+func (m *Metrics) Failed(labels Labels, _ error) {
+	m.add(MetricFailed, labels, 1)
+}
+
+// Dropped implements Observer.
+func (m *Metrics) Dropped(labels Labels, _ error) {
+	m.add(MetricDropped, labels, 1)
+}
+
+// Retried implements Observer.
+func (m *Metrics) Retried(labels Labels, _ int) {
+	m.add(MetricRetried, labels, 1)
+}
+
+// QueueDepth implements Observer.
+func (m *Metrics) QueueDepth(labels Labels, depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.series[seriesKey{name: MetricQueueDepth, labels: labels}] = float64(depth)
+}
+
+// Snapshot returns the current value of every series, sorted by metric name
+// and labels.
+func (m *Metrics) Snapshot() []Sample {
+	m.mu.Lock()
+	samples := make([]Sample, 0, len(m.series))
+
+	for key, value := range m.series {
+		samples = append(samples, Sample{Name: key.name, Labels: key.labels, Value: value})
+	}
+	m.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		if samples[i].Name != samples[j].Name {
+			return samples[i].Name < samples[j].Name
+		}
+
+		return formatLabels(samples[i].Labels) < formatLabels(samples[j].Labels)
+	})
+
+	return samples
+}
+
+// Value returns the current value of the series of the given metric and
+// labels, zero if never observed.
+func (m *Metrics) Value(name string, labels Labels) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.series[seriesKey{name: name, labels: labels}]
+}
+
+func (m *Metrics) add(name string, labels Labels, delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.series[seriesKey{name: name, labels: labels}] += delta
+}
@@ -0,0 +1,77 @@
+package observe_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events/observe"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("GIVEN metrics fed through a multi observer", func(t *testing.T) {
+		metrics := observe.NewMetrics()
+		obs := observe.Multi(observe.Nop{}, metrics)
+
+		handler := observe.Labels{Component: "dispatcher", Topic: "orders", Handler: "audit", EventType: "order.created"}
+		queue := observe.Labels{Component: "queue", Name: `say "hi"`}
+
+		obs.Dispatched(handler)
+		obs.Dispatched(handler)
+		obs.Delivered(handler, 250*time.Millisecond)
+		obs.Failed(handler, errors.New("failed"))
+		obs.Retried(handler, 2)
+		obs.Dropped(queue, errors.New("dropped"))
+		obs.QueueDepth(queue, 5)
+		obs.QueueDepth(queue, 3)
+
+		t.Run("WHEN reading values THEN observations are aggregated", func(t *testing.T) {
+			require.EqualValues(t, 2, metrics.Value(observe.MetricDispatched, handler))
+			require.EqualValues(t, 1, metrics.Value(observe.MetricDelivered, handler))
+			require.EqualValues(t, 0.25, metrics.Value(observe.MetricDeliveryDuration+"_sum", handler))
+			require.EqualValues(t, 1, metrics.Value(observe.MetricFailed, handler))
+			require.EqualValues(t, 1, metrics.Value(observe.MetricRetried, handler))
+			require.EqualValues(t, 1, metrics.Value(observe.MetricDropped, queue))
+			require.EqualValues(t, 3, metrics.Value(observe.MetricQueueDepth, queue))
+		})
+
+		t.Run("WHEN exporting in Prometheus text format THEN every family is described AND labels are escaped", func(t *testing.T) {
+			var sb strings.Builder
+
+			require.NoError(t, metrics.WritePrometheus(&sb))
+
+			out := sb.String()
+			labels := `{component="dispatcher",topic="orders",handler="audit",event_type="order.created"}`
+
+			require.Contains(t, out, "# TYPE events_dispatched_total counter\n")
+			require.Contains(t, out, "events_dispatched_total"+labels+" 2\n")
+			require.Contains(t, out, "# TYPE events_delivery_duration_seconds summary\n")
+			require.Contains(t, out, "events_delivery_duration_seconds_sum"+labels+" 0.25\n")
+			require.Contains(t, out, "events_delivery_duration_seconds_count"+labels+" 1\n")
+			require.Contains(t, out, "# TYPE events_queue_depth gauge\n")
+			require.Contains(t, out, `events_queue_depth{component="queue",name="say \"hi\""} 3`+"\n")
+			require.Equal(t, 1, strings.Count(out, "# TYPE events_delivery_duration_seconds "))
+		})
+
+		t.Run("WHEN serving over HTTP THEN the Prometheus content type is used", func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			metrics.PrometheusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			require.Equal(t, observe.PrometheusContentType, rec.Header().Get("Content-Type"))
+			require.Contains(t, rec.Body.String(), "events_failed_total")
+		})
+
+		t.Run("WHEN exporting through expvar THEN values are keyed by metric and labels", func(t *testing.T) {
+			var out map[string]map[string]float64
+
+			require.NoError(t, json.Unmarshal([]byte(metrics.Expvar().String()), &out))
+			require.EqualValues(t, 3, out[observe.MetricQueueDepth][`{component="queue",name="say \"hi\""}`])
+			require.Len(t, out[observe.MetricDispatched], 1)
+		})
+	})
+}
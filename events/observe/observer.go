@@ -0,0 +1,118 @@
+// Package observe provides hooks for observing how events flow through
+// dispatchers and sinks, along with exporters that turn such observations into
+// metrics, see Metrics.
+package observe
+
+import "time"
+
+// Labels identifies the component an observation comes from, and the event
+// it refers to. Fields not applying to an observation are left empty.
+type Labels struct {
+	// Component is the kind of component being observed, such as
+	// "dispatcher" or "queue".
+	Component string
+
+	// Name identifies an instance of the component, if given one.
+	Name string
+
+	// Topic is the topic the event was dispatched to.
+	Topic string
+
+	// Handler identifies the handler the event was delivered to.
+	Handler string
+
+	// EventType is the name of the type of the event, see events.TypeName.
+	EventType string
+}
+
+// Observer is notified about events flowing through a component. Observers
+// are invoked synchronously, so they must be fast and safe for concurrent use.
+type Observer interface {
+	// Dispatched is called when an event is accepted by a component.
+	Dispatched(labels Labels)
+
+	// Delivered is called when an event is successfully handed over to a
+	// handler or downstream sink, along with the time it took.
+	Delivered(labels Labels, elapsed time.Duration)
+
+	// Failed is called when handing over an event fails, once retries, if
+	// any, are exhausted.
+	Failed(labels Labels, err error)
+
+	// Dropped is called when a component gives up on an event, which will
+	// not be delivered.
+	Dropped(labels Labels, err error)
+
+	// Retried is called before every new attempt of handing over an event,
+	// starting at attempt 2.
+	Retried(labels Labels, attempt int)
+
+	// QueueDepth is called when the number of events waiting in the queue of
+	// a component changes.
+	QueueDepth(labels Labels, depth int)
+}
+
+// Nop is an Observer that ignores every observation. It can be embedded in
+// observers only interested in some observations.
+type Nop struct{}
+
+// Dispatched implements Observer.
+func (Nop) Dispatched(Labels) {}
+
+// Delivered implements Observer.
+func (Nop) Delivered(Labels, time.Duration) {}
+
+// Failed implements Observer.
+func (Nop) Failed(Labels, error) {}
+
+// Dropped implements Observer.
+func (Nop) Dropped(Labels, error) {}
+
+// Retried implements Observer.
+func (Nop) Retried(Labels, int) {}
+
+// QueueDepth implements Observer.
+func (Nop) QueueDepth(Labels, int) {}
+
+type multi []Observer
+
+// Multi builds an Observer that notifies every given observer, in order.
+func Multi(observers ...Observer) Observer {
+	return multi(observers)
+}
+
+func (m multi) Dispatched(labels Labels) {
+	for i := range m {
+		m[i].Dispatched(labels)
+	}
+}
+
+func (m multi) Delivered(labels Labels, elapsed time.Duration) {
+	for i := range m {
+		m[i].Delivered(labels, elapsed)
+	}
+}
+
+func (m multi) Failed(labels Labels, err error) {
+	for i := range m {
+		m[i].Failed(labels, err)
+	}
+}
+
+func (m multi) Dropped(labels Labels, err error) {
+	for i := range m {
+		m[i].Dropped(labels, err)
+	}
+}
+
+func (m multi) Retried(labels Labels, attempt int) {
+	for i := range m {
+		m[i].Retried(labels, attempt)
+	}
+}
+
+func (m multi) QueueDepth(labels Labels, depth int) {
+	for i := range m {
+		m[i].QueueDepth(labels, depth)
+	}
+}
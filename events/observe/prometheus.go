@@ -0,0 +1,94 @@
+package observe
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition
+// format written by WritePrometheus.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes every collected metric into the given writer using
+// the Prometheus text exposition format, so they can be scraped without
+// depending on the Prometheus client library.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	samples := m.Snapshot()
+	bw := bufio.NewWriter(w)
+
+	for _, fam := range families {
+		var written bool
+
+		for _, s := range samples {
+			if s.Name != fam.name && !strings.HasPrefix(s.Name, fam.name+"_") {
+				continue
+			}
+
+			if fam.kind != "summary" && s.Name != fam.name {
+				continue
+			}
+
+			if !written {
+				written = true
+
+				_, _ = bw.WriteString("# HELP " + fam.name + " " + fam.help + "\n")
+				_, _ = bw.WriteString("# TYPE " + fam.name + " " + fam.kind + "\n")
+			}
+
+			_, _ = bw.WriteString(s.Name + formatLabels(s.Labels) + " " + strconv.FormatFloat(s.Value, 'g', -1, 64) + "\n")
+		}
+	}
+
+	return bw.Flush()
+}
+
+// PrometheusHandler returns an HTTP handler serving the collected metrics
+// using the Prometheus text exposition format, see WritePrometheus.
+func (m *Metrics) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+
+		if err := m.WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// formatLabels formats the non-empty given labels as Prometheus does, such
+// as `{component="queue",name="audit"}`.
+func formatLabels(labels Labels) string {
+	pairs := [...]struct{ name, value string }{
+		{"component", labels.Component},
+		{"name", labels.Name},
+		{"topic", labels.Topic},
+		{"handler", labels.Handler},
+		{"event_type", labels.EventType},
+	}
+
+	var sb strings.Builder
+
+	for _, p := range pairs {
+		if p.value == "" {
+			continue
+		}
+
+		if sb.Len() == 0 {
+			sb.WriteByte('{')
+		} else {
+			sb.WriteByte(',')
+		}
+
+		sb.WriteString(p.name + `="` + labelEscaper.Replace(p.value) + `"`)
+	}
+
+	if sb.Len() > 0 {
+		sb.WriteByte('}')
+	}
+
+	return sb.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -1,6 +1,16 @@
 package events
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrInvalidSavepoint is returned when rolling back to a savepoint that no
+// longer exists, for instance because the events it covers were already pulled
+// or rolled back.
+var ErrInvalidSavepoint = errors.New("invalid savepoint")
 
 // Recorder defines an element capable of recording events.
 type Recorder interface {
@@ -12,36 +22,120 @@ type Recorder interface {
 
 	// ClearChanges clears the list of recorded events.
 	ClearChanges()
+
+	// PullChanges atomically retrieves and clears the list of events tracked
+	// so far, so no event recorded concurrently is lost between both steps.
+	PullChanges() []Event
+
+	// Savepoint marks the current position in the list of recorded events.
+	Savepoint() Savepoint
+
+	// RollbackTo discards every event recorded after the given savepoint,
+	// keeping the ones recorded before it.
+	RollbackTo(sp Savepoint) error
+}
+
+// Savepoint is an opaque position in the list of events of a Recorder.
+type Savepoint struct {
+	seq     uint64
+	version int
+}
+
+// EnvelopeRecorder defines a Recorder that wraps every recorded event in an
+// Envelope.
+type EnvelopeRecorder interface {
+	Recorder
+
+	// RecordContext tracks an event in the list of events, stamping its
+	// envelope with the correlation data found in the given context.
+	RecordContext(ctx context.Context, event Event)
+
+	// Envelopes retrieves the envelopes of the events tracked so far.
+	Envelopes() []Envelope
+
+	// PullEnvelopes atomically retrieves and clears the envelopes of the events
+	// tracked so far.
+	PullEnvelopes() []Envelope
 }
 
 // BaseRecorder is a trait that implements the common functionality for the
-// Recorder interface. This object can be safely shared by multiple goroutines.
+// EnvelopeRecorder interface. This object can be safely shared by multiple
+// goroutines.
 type BaseRecorder struct {
-	events []Event
-	mu     sync.RWMutex
+	entries       []recorded
+	aggregateID   string
+	aggregateType string
+	version       int
+
+	// seq is the sequence number of the last recorded event, base is the
+	// sequence number of the last event pulled or cleared, and discarded
+	// holds the sequence ranges dropped by rollbacks since then.
+	seq       uint64
+	base      uint64
+	discarded []seqRange
+	mu        sync.RWMutex
+}
+
+type recorded struct {
+	seq      uint64
+	envelope Envelope
+}
+
+type seqRange struct {
+	from, to uint64
+}
+
+// SetAggregate sets the aggregate information stamped on the envelopes of the
+// events recorded from now on. The given version is the current version of
+// the aggregate, and it is incremented on each recorded event.
+func (b *BaseRecorder) SetAggregate(id, aggregateType string, version int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.aggregateID = id
+	b.aggregateType = aggregateType
+	b.version = version
 }
 
 // Record tracks an event in the list of events.
 func (b *BaseRecorder) Record(event Event) {
+	b.RecordContext(context.Background(), event)
+}
+
+// RecordContext tracks an event in the list of events, stamping its envelope
+// with the correlation data found in the given context.
+func (b *BaseRecorder) RecordContext(ctx context.Context, event Event) {
+	env := Wrap(ctx, event)
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if b.events == nil {
-		b.events = make([]Event, 0)
+	if env.AggregateID == "" {
+		b.version++
+
+		env.AggregateID = b.aggregateID
+		env.AggregateType = b.aggregateType
+		env.AggregateVersion = b.version
 	}
 
-	b.events = append(b.events, event)
+	b.seq++
+	b.entries = append(b.entries, recorded{seq: b.seq, envelope: env})
 }
 
-// Changes retrieves the list of event tracked so far.
+// Changes retrieves a copy of the list of event tracked so far.
 func (b *BaseRecorder) Changes() []Event {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	out := make([]Event, len(b.events))
-	copy(out, b.events)
+	return b.payloads()
+}
 
-	return b.events
+// Envelopes retrieves a copy of the envelopes of the events tracked so far.
+func (b *BaseRecorder) Envelopes() []Envelope {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.envelopes()
 }
 
 // ClearChanges clears the list of recorded events.
@@ -49,5 +143,95 @@ func (b *BaseRecorder) ClearChanges() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.events = make([]Event, 0)
+	b.reset()
+}
+
+// PullChanges atomically retrieves and clears the list of events tracked so
+// far.
+func (b *BaseRecorder) PullChanges() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := b.payloads()
+	b.reset()
+
+	return out
+}
+
+// PullEnvelopes atomically retrieves and clears the envelopes of the events
+// tracked so far.
+func (b *BaseRecorder) PullEnvelopes() []Envelope {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := b.envelopes()
+	b.reset()
+
+	return out
+}
+
+// Savepoint marks the current position in the list of recorded events.
+func (b *BaseRecorder) Savepoint() Savepoint {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return Savepoint{seq: b.seq, version: b.version}
+}
+
+// RollbackTo discards every event recorded after the given savepoint, and
+// restores the aggregate version to the one at that point. Savepoints taken
+// after the given one are invalidated, as well as every savepoint taken before
+// the last time changes were pulled or cleared.
+func (b *BaseRecorder) RollbackTo(sp Savepoint) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sp.seq < b.base || sp.seq > b.seq {
+		return fmt.Errorf("%w: events were already pulled or cleared", ErrInvalidSavepoint)
+	}
+
+	for _, r := range b.discarded {
+		if sp.seq > r.from && sp.seq <= r.to {
+			return fmt.Errorf("%w: savepoint was already rolled back", ErrInvalidSavepoint)
+		}
+	}
+
+	keep := len(b.entries)
+	for keep > 0 && b.entries[keep-1].seq > sp.seq {
+		keep--
+	}
+
+	b.entries = b.entries[:keep]
+	b.version = sp.version
+	b.discarded = append(b.discarded, seqRange{from: sp.seq, to: b.seq})
+
+	// Skip a sequence number so savepoints taken from now on never fall within
+	// the discarded range.
+	b.seq++
+
+	return nil
+}
+
+func (b *BaseRecorder) payloads() []Event {
+	out := make([]Event, len(b.entries))
+	for i := range b.entries {
+		out[i] = b.entries[i].envelope.Payload
+	}
+
+	return out
+}
+
+func (b *BaseRecorder) envelopes() []Envelope {
+	out := make([]Envelope, len(b.entries))
+	for i := range b.entries {
+		out[i] = b.entries[i].envelope
+	}
+
+	return out
+}
+
+func (b *BaseRecorder) reset() {
+	b.entries = make([]recorded, 0)
+	b.base = b.seq
+	b.discarded = nil
 }
@@ -2,6 +2,7 @@ package events_test
 
 import (
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -52,3 +53,161 @@ func TestBaseRecorder(t *testing.T) {
 		})
 	})
 }
+
+func TestBaseRecorderChanges(t *testing.T) {
+	t.Run("GIVEN a recorder with events WHEN mutating the returned changes THEN the recorder is not affected", func(t *testing.T) {
+		recorder := &events.BaseRecorder{}
+		recorder.Record("a")
+		recorder.Record("b")
+
+		changes := recorder.Changes()
+		changes[0] = "z"
+		_ = append(changes[:1], "y")
+
+		envelopes := recorder.Envelopes()
+		envelopes[1].Payload = "x"
+
+		require.Equal(t, []events.Event{"a", "b"}, recorder.Changes())
+	})
+}
+
+func TestBaseRecorderPullChanges(t *testing.T) {
+	t.Run("GIVEN a recorder being written by multiple goroutines WHEN pulling changes concurrently THEN no event is lost nor pulled twice", func(t *testing.T) {
+		const writers, perWriter = 8, 500
+
+		recorder := &events.BaseRecorder{}
+		done := make(chan struct{})
+		pulled := make(chan []events.Event)
+
+		var written int64
+
+		go func() {
+			var out []events.Event
+
+			for {
+				select {
+				case <-done:
+					pulled <- append(out, recorder.PullChanges()...)
+
+					return
+				default:
+					out = append(out, recorder.PullChanges()...)
+				}
+			}
+		}()
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < perWriter; j++ {
+					recorder.Record(domain.NewID())
+					atomic.AddInt64(&written, 1)
+				}
+			}()
+		}
+
+		wg.Wait()
+		close(done)
+
+		got := <-pulled
+		require.Len(t, got, int(atomic.LoadInt64(&written)))
+
+		seen := make(map[events.Event]struct{}, len(got))
+		for _, e := range got {
+			seen[e] = struct{}{}
+		}
+
+		require.Len(t, seen, writers*perWriter)
+		require.Empty(t, recorder.Changes())
+	})
+
+	t.Run("GIVEN a recorder with envelopes WHEN pulling envelopes THEN they are returned AND the recorder is now empty", func(t *testing.T) {
+		recorder := &events.BaseRecorder{}
+		recorder.Record("a")
+
+		envelopes := recorder.PullEnvelopes()
+		require.Len(t, envelopes, 1)
+		require.Equal(t, "a", envelopes[0].Payload)
+		require.Empty(t, recorder.Envelopes())
+	})
+}
+
+func TestBaseRecorderSavepoints(t *testing.T) {
+	t.Run("GIVEN a recorder with events recorded before and after a savepoint", func(t *testing.T) {
+		recorder := &events.BaseRecorder{}
+		recorder.SetAggregate(gofakeit.UUID(), "test", 0)
+		recorder.Record("a")
+
+		sp := recorder.Savepoint()
+
+		recorder.Record("b")
+		recorder.Record("c")
+
+		inner := recorder.Savepoint()
+
+		t.Run("WHEN rolling back to the savepoint THEN only the earlier events are kept AND versions continue from there", func(t *testing.T) {
+			require.NoError(t, recorder.RollbackTo(sp))
+			require.Equal(t, []events.Event{"a"}, recorder.Changes())
+
+			recorder.Record("d")
+
+			envelopes := recorder.Envelopes()
+			require.Equal(t, 2, envelopes[1].AggregateVersion)
+		})
+
+		t.Run("WHEN rolling back to a savepoint taken after the one rolled back THEN it fails", func(t *testing.T) {
+			require.ErrorIs(t, recorder.RollbackTo(inner), events.ErrInvalidSavepoint)
+		})
+
+		t.Run("WHEN taking a new savepoint and rolling back to it THEN later events are discarded", func(t *testing.T) {
+			again := recorder.Savepoint()
+			recorder.Record("e")
+
+			require.NoError(t, recorder.RollbackTo(again))
+			require.Equal(t, []events.Event{"a", "d"}, recorder.Changes())
+		})
+
+		t.Run("WHEN rolling back to a savepoint whose events were pulled THEN it fails", func(t *testing.T) {
+			before := recorder.Savepoint()
+			recorder.Record("f")
+			recorder.PullChanges()
+
+			require.ErrorIs(t, recorder.RollbackTo(before), events.ErrInvalidSavepoint)
+			require.ErrorIs(t, recorder.RollbackTo(sp), events.ErrInvalidSavepoint)
+		})
+	})
+
+	t.Run("GIVEN a recorder being written by multiple goroutines WHEN rolling back concurrently THEN the recorder stays consistent", func(t *testing.T) {
+		recorder := &events.BaseRecorder{}
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 100; j++ {
+					sp := recorder.Savepoint()
+					recorder.Record(domain.NewID())
+
+					if j%2 == 0 {
+						_ = recorder.RollbackTo(sp)
+					}
+
+					_ = recorder.Changes()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		require.LessOrEqual(t, len(recorder.Changes()), 8*100)
+	})
+}
@@ -0,0 +1,167 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Registry-related errors.
+var (
+	// ErrEventNotRegistered is returned when looking up an event name or type
+	// unknown to a registry.
+	ErrEventNotRegistered = errors.New("event not registered")
+
+	// ErrEventAlreadyRegistered is returned when registering an event name or
+	// type already known by a registry.
+	ErrEventAlreadyRegistered = errors.New("event already registered")
+)
+
+// Registry maps stable event names to Go types, so events can be serialized
+// by name and decoded back into their concrete type. Registries also hold the
+// chain of upcasters used to transform old versions of raw payloads into the
+// current version, see RegisterUpcaster.
+//
+// This object can be safely shared by multiple goroutines.
+type Registry struct {
+	byName    map[string]reflect.Type
+	byType    map[reflect.Type]string
+	upcasters map[string]Upcaster
+	mu        sync.RWMutex
+}
+
+// NewRegistry builds a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byName:    make(map[string]reflect.Type),
+		byType:    make(map[reflect.Type]string),
+		upcasters: make(map[string]Upcaster),
+	}
+}
+
+// Register registers the type of the given event using its default name, as
+// returned by TypeName. Events should implement the Namer interface to get
+// names that survive refactors.
+//
+// Events are usually registered by value, e.g. `Register(OrderCreated{})`,
+// except for types whose methods have pointer receivers, such as proto
+// messages, which must be registered by pointer.
+func (r *Registry) Register(events ...Event) error {
+	for i := range events {
+		if err := r.RegisterName(TypeName(events[i]), events[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterName registers the type of the given event using the given name.
+func (r *Registry) RegisterName(name string, event Event) error {
+	event = Unwrap(event)
+
+	if name == "" || event == nil {
+		return fmt.Errorf("a non-empty name and a non-nil event must be provided")
+	}
+
+	t := reflect.TypeOf(event)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.byName[name]; ok {
+		return fmt.Errorf("%w: name `%s` is already taken by %s", ErrEventAlreadyRegistered, name, existing)
+	}
+
+	if existing, ok := r.byType[t]; ok {
+		return fmt.Errorf("%w: type %s is already registered as `%s`", ErrEventAlreadyRegistered, t, existing)
+	}
+
+	r.byName[name] = t
+	r.byType[t] = name
+
+	return nil
+}
+
+// MustRegister is like Register but panics if registration fails. Intended to
+// be used in package initialization.
+func (r *Registry) MustRegister(events ...Event) {
+	if err := r.Register(events...); err != nil {
+		panic(err)
+	}
+}
+
+// NameOf returns the name under which the type of the given event was
+// registered. Envelopes are described by the name of their payload.
+func (r *Registry) NameOf(event Event) (string, error) {
+	event = Unwrap(event)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name, ok := r.byType[reflect.TypeOf(event)]
+	if !ok {
+		return "", fmt.Errorf("%w: type %T", ErrEventNotRegistered, event)
+	}
+
+	return name, nil
+}
+
+// TypeOf returns the Go type registered under the given name.
+func (r *Registry) TypeOf(name string) (reflect.Type, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: name `%s`", ErrEventNotRegistered, name)
+	}
+
+	return t, nil
+}
+
+// Decode builds a new event of the type registered under the given name, and
+// fills it by calling the given function with a pointer to it. This is meant
+// to be used with decoding functions such as json.Unmarshal.
+//
+// The returned event has exactly the registered type, i.e. a value for types
+// registered by value, or a pointer for types registered by pointer.
+func (r *Registry) Decode(name string, decode func(target interface{}) error) (Event, error) {
+	t, err := r.TypeOf(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Kind() == reflect.Ptr {
+		target := reflect.New(t.Elem())
+		if dErr := decode(target.Interface()); dErr != nil {
+			return nil, fmt.Errorf("%w: could not decode event `%s`", dErr, name)
+		}
+
+		return target.Interface(), nil
+	}
+
+	target := reflect.New(t)
+	if dErr := decode(target.Interface()); dErr != nil {
+		return nil, fmt.Errorf("%w: could not decode event `%s`", dErr, name)
+	}
+
+	return target.Elem().Interface(), nil
+}
+
+// Names returns every registered name, sorted alphabetically.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		out = append(out, name)
+	}
+
+	sort.Strings(out)
+
+	return out
+}
@@ -0,0 +1,60 @@
+package events_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("GIVEN a registry with a named event and an unnamed event", func(t *testing.T) {
+		reg := events.NewRegistry()
+
+		require.NoError(t, reg.Register(namedEvent{}))
+		require.NoError(t, reg.RegisterName("user.registered", userRegistered{}))
+
+		t.Run("WHEN asking for names THEN event names are used", func(t *testing.T) {
+			require.Equal(t, []string{"user.named", "user.registered"}, reg.Names())
+
+			name, err := reg.NameOf(userRegistered{Name: "a"})
+			require.NoError(t, err)
+			require.Equal(t, "user.registered", name)
+		})
+
+		t.Run("WHEN registering the same name or type again THEN registration fails", func(t *testing.T) {
+			require.ErrorIs(t, reg.Register(namedEvent{}), events.ErrEventAlreadyRegistered)
+			require.ErrorIs(t, reg.RegisterName("other", userRegistered{}), events.ErrEventAlreadyRegistered)
+		})
+
+		t.Run("WHEN asking for an unknown type THEN lookup fails", func(t *testing.T) {
+			_, err := reg.NameOf(&userRegistered{})
+			require.ErrorIs(t, err, events.ErrEventNotRegistered)
+
+			_, err = reg.TypeOf("unknown")
+			require.ErrorIs(t, err, events.ErrEventNotRegistered)
+		})
+
+		t.Run("WHEN decoding by name THEN a concrete value is returned", func(t *testing.T) {
+			event, err := reg.Decode("user.registered", func(target interface{}) error {
+				return json.Unmarshal([]byte(`{"Name":"john"}`), target)
+			})
+
+			require.NoError(t, err)
+			require.Equal(t, userRegistered{Name: "john"}, event)
+		})
+	})
+
+	t.Run("GIVEN a registry with an event registered by pointer WHEN decoding by name THEN a pointer is returned", func(t *testing.T) {
+		reg := events.NewRegistry()
+		require.NoError(t, reg.RegisterName("user.registered", &userRegistered{}))
+
+		event, err := reg.Decode("user.registered", func(target interface{}) error {
+			return json.Unmarshal([]byte(`{"Name":"john"}`), target)
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, &userRegistered{Name: "john"}, event)
+	})
+}
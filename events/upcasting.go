@@ -0,0 +1,99 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// versionSeparator separates the base name of an event from its version.
+const versionSeparator = ".v"
+
+// VersionedName builds the name of the given version of an event. Version 1,
+// or lower, is denoted by the base name itself, so events that were never
+// versioned keep their original name. Examples:
+//
+// * VersionedName("order.line_added", 1) = "order.line_added".
+// * VersionedName("order.line_added", 2) = "order.line_added.v2".
+func VersionedName(base string, version int) string {
+	if version <= 1 {
+		return base
+	}
+
+	return base + versionSeparator + strconv.Itoa(version)
+}
+
+// ParseVersionedName splits the given event name into its base name and its
+// version, the inverse of VersionedName. Names without a version suffix are
+// considered to be version 1.
+func ParseVersionedName(name string) (base string, version int) {
+	idx := strings.LastIndex(name, versionSeparator)
+	if idx < 0 {
+		return name, 1
+	}
+
+	v, err := strconv.Atoi(name[idx+len(versionSeparator):])
+	if err != nil || v < 1 {
+		return name, 1
+	}
+
+	return name[:idx], v
+}
+
+// Upcaster transforms the raw payload of a given version of an event into the
+// raw payload of the next version.
+type Upcaster func(payload []byte) ([]byte, error)
+
+// RegisterUpcaster registers a function that transforms raw payloads of the
+// event named as given into payloads of its next version. For example, an
+// upcaster registered for "order.line_added" transforms payloads into the
+// "order.line_added.v2" shape.
+//
+// Upcasters are chained, so a payload of version N is transformed into version
+// N+1, then N+2, and so on while upcasters are found.
+func (r *Registry) RegisterUpcaster(name string, upcaster Upcaster) error {
+	if upcaster == nil {
+		return fmt.Errorf("a non-nil upcaster must be provided")
+	}
+
+	base, version := ParseVersionedName(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := VersionedName(base, version)
+	if _, exists := r.upcasters[key]; exists {
+		return fmt.Errorf("%w: upcaster for `%s` is already registered", ErrEventAlreadyRegistered, key)
+	}
+
+	r.upcasters[key] = upcaster
+
+	return nil
+}
+
+// Upcast applies the chain of registered upcasters to the given raw payload of
+// the event named as given, and returns the name and payload of the latest
+// version reachable. Payloads with no registered upcaster are returned as is.
+func (r *Registry) Upcast(name string, payload []byte) (string, []byte, error) {
+	base, version := ParseVersionedName(name)
+
+	for {
+		current := VersionedName(base, version)
+
+		r.mu.RLock()
+		upcaster, ok := r.upcasters[current]
+		r.mu.RUnlock()
+
+		if !ok {
+			return current, payload, nil
+		}
+
+		next, err := upcaster(payload)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: could not upcast event `%s` to version %d", err, current, version+1)
+		}
+
+		payload = next
+		version++
+	}
+}
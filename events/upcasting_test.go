@@ -0,0 +1,118 @@
+package events_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events"
+)
+
+func TestVersionedName(t *testing.T) {
+	require.Equal(t, "order.line_added", events.VersionedName("order.line_added", 1))
+	require.Equal(t, "order.line_added.v3", events.VersionedName("order.line_added", 3))
+
+	base, version := events.ParseVersionedName("order.line_added.v3")
+	require.Equal(t, "order.line_added", base)
+	require.Equal(t, 3, version)
+
+	base, version = events.ParseVersionedName("order.line_added")
+	require.Equal(t, "order.line_added", base)
+	require.Equal(t, 1, version)
+
+	base, version = events.ParseVersionedName("order.vip")
+	require.Equal(t, "order.vip", base)
+	require.Equal(t, 1, version)
+}
+
+func TestUpcasting(t *testing.T) {
+	t.Run("GIVEN a registry with the third version of an event AND upcasters from version 1 and 2", func(t *testing.T) {
+		reg := events.NewRegistry()
+		require.NoError(t, reg.Register(lineAddedV3{}))
+
+		// v1 -> v2: quantity renamed from "qty" to "quantity".
+		require.NoError(t, reg.RegisterUpcaster("order.line_added", func(payload []byte) ([]byte, error) {
+			var v1 map[string]interface{}
+			if err := json.Unmarshal(payload, &v1); err != nil {
+				return nil, err
+			}
+
+			v1["quantity"] = v1["qty"]
+			delete(v1, "qty")
+
+			return json.Marshal(v1)
+		}))
+
+		// v2 -> v3: product and quantity moved into a nested line.
+		require.NoError(t, reg.RegisterUpcaster("order.line_added.v2", func(payload []byte) ([]byte, error) {
+			var v2 map[string]interface{}
+			if err := json.Unmarshal(payload, &v2); err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(map[string]interface{}{
+				"orderId": v2["orderId"],
+				"line": map[string]interface{}{
+					"productId": v2["productId"],
+					"quantity":  v2["quantity"],
+				},
+			})
+		}))
+
+		t.Run("WHEN upcasting a version 1 payload THEN it is decoded into the current struct", func(t *testing.T) {
+			name, payload, err := reg.Upcast("order.line_added", []byte(`{"orderId":"o1","productId":"p1","qty":3}`))
+			require.NoError(t, err)
+			require.Equal(t, "order.line_added.v3", name)
+
+			event, err := reg.Decode(name, func(target interface{}) error {
+				return json.Unmarshal(payload, target)
+			})
+			require.NoError(t, err)
+			require.Equal(t, lineAddedV3{OrderID: "o1", Line: lineV3{ProductID: "p1", Quantity: 3}}, event)
+		})
+
+		t.Run("WHEN upcasting a current version payload THEN it is returned untouched", func(t *testing.T) {
+			raw := []byte(`{"orderId":"o1"}`)
+
+			name, payload, err := reg.Upcast("order.line_added.v3", raw)
+			require.NoError(t, err)
+			require.Equal(t, "order.line_added.v3", name)
+			require.Equal(t, raw, payload)
+		})
+
+		t.Run("WHEN registering a second upcaster for the same version THEN registration fails", func(t *testing.T) {
+			err := reg.RegisterUpcaster("order.line_added.v2", func(payload []byte) ([]byte, error) {
+				return payload, nil
+			})
+
+			require.ErrorIs(t, err, events.ErrEventAlreadyRegistered)
+		})
+	})
+
+	t.Run("GIVEN a registry with a failing upcaster WHEN upcasting THEN the error is returned", func(t *testing.T) {
+		errBroken := errors.New("broken")
+		reg := events.NewRegistry()
+
+		require.NoError(t, reg.RegisterUpcaster("order.line_added", func(payload []byte) ([]byte, error) {
+			return nil, errBroken
+		}))
+
+		_, _, err := reg.Upcast("order.line_added", []byte(`{}`))
+		require.ErrorIs(t, err, errBroken)
+	})
+}
+
+type lineV3 struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+}
+
+type lineAddedV3 struct {
+	OrderID string `json:"orderId"`
+	Line    lineV3 `json:"line"`
+}
+
+func (lineAddedV3) EventName() string {
+	return events.VersionedName("order.line_added", 3)
+}
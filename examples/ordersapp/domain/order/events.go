@@ -7,8 +7,20 @@ type CreatedEvent struct {
 	OrderID domain.ID
 }
 
+// EventName implements the events.Namer interface.
+func (CreatedEvent) EventName() string {
+	return "order.created"
+}
+
 // LineAddedEvent a domain event that is raised when a line is added to an order.
 type LineAddedEvent struct {
 	OrderID domain.ID
 	Line    Line
 }
+
+// EventName implements the events.Namer interface. Bump the version using
+// events.VersionedName, and register an upcaster for the previous one, when
+// the shape of this event changes.
+func (LineAddedEvent) EventName() string {
+	return "order.line_added"
+}
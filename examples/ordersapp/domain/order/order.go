@@ -26,6 +26,7 @@ func NewOrder(id domain.ID) *Order {
 		lines: make([]Line, 0),
 	}
 
+	ord.SetAggregate(id.String(), "order", 0)
 	ord.Record(CreatedEvent{
 		OrderID: id,
 	})
@@ -46,12 +46,10 @@ func (h handler) Handle(ctx context.Context, id domain.ID, lines ...order.Line)
 	return nil
 }
 
-func (h handler) dispatchEvents(ctx context.Context, recorder events.Recorder) {
-	for _, event := range recorder.Changes() {
-		if err := h.dsp.Dispatch(ctx, event); err != nil {
+func (h handler) dispatchEvents(ctx context.Context, recorder events.EnvelopeRecorder) {
+	for _, env := range recorder.PullEnvelopes() {
+		if err := h.dsp.Dispatch(ctx, env); err != nil {
 			println("event dispatch failed:", err.Error())
 		}
 	}
-
-	recorder.ClearChanges()
 }
@@ -1,6 +1,6 @@
 module github.com/tangelo-labs/go-domain
 
-go 1.20
+go 1.21
 
 require (
 	github.com/Avalanche-io/counter v0.0.0-20180124180526-1336089e985a
@@ -0,0 +1,97 @@
+// Package bus provides the routing shared by the command and query buses,
+// which send each message to the single handler registered for its type.
+package bus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Errors holds the errors reported by a bus, so each flavor of bus keeps its
+// own sentinels.
+type Errors struct {
+	// NotFound is reported when dispatching a message with no handler.
+	NotFound error
+
+	// AlreadyRegistered is reported when registering a second handler for a
+	// same message type.
+	AlreadyRegistered error
+
+	// InvalidType is reported when registering a handler for a message type
+	// that cannot be routed, such as interfaces.
+	InvalidType error
+}
+
+// Bus routes messages of type M by their dynamic type to handlers of type H,
+// wrapped by middlewares of type W.
+//
+// This object can be safely shared by multiple goroutines.
+type Bus[M any, H ~func(context.Context, M) (interface{}, error), W ~func(H) H] struct {
+	kind        string
+	errs        Errors
+	handlers    map[reflect.Type]H
+	middlewares []W
+	mu          sync.RWMutex
+}
+
+// New builds a new bus with the given middlewares. The given kind names the
+// messages routed by the bus, such as "command", in error messages.
+func New[M any, H ~func(context.Context, M) (interface{}, error), W ~func(H) H](kind string, errs Errors, mws ...W) *Bus[M, H, W] {
+	return &Bus[M, H, W]{
+		kind:        kind,
+		errs:        errs,
+		handlers:    make(map[reflect.Type]H),
+		middlewares: mws,
+	}
+}
+
+// Dispatch sends the given message to its handler, through every middleware,
+// and returns its result.
+func (b *Bus[M, H, W]) Dispatch(ctx context.Context, msg M) (interface{}, error) {
+	b.mu.RLock()
+	handler, ok := b.handlers[reflect.TypeOf(msg)]
+	mws := b.middlewares
+	b.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: for %s %T", b.errs.NotFound, b.kind, msg)
+	}
+
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+
+	return handler(ctx, msg)
+}
+
+// Use appends the given middlewares to the chain applied to every handler.
+func (b *Bus[M, H, W]) Use(mws ...W) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	chain := make([]W, 0, len(b.middlewares)+len(mws))
+	chain = append(chain, b.middlewares...)
+	chain = append(chain, mws...)
+
+	b.middlewares = chain
+}
+
+// RegisterHandler registers the handler for messages of the given type.
+func (b *Bus[M, H, W]) RegisterHandler(msgType reflect.Type, handler H) error {
+	if msgType.Kind() == reflect.Interface {
+		return fmt.Errorf("%w: %s type cannot be an interface, got %s", b.errs.InvalidType, b.kind, msgType)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.handlers[msgType]; exists {
+		return fmt.Errorf("%w: for %s %s", b.errs.AlreadyRegistered, b.kind, msgType)
+	}
+
+	b.handlers[msgType] = handler
+
+	return nil
+}
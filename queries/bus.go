@@ -0,0 +1,111 @@
+// Package queries provides a query bus for invoking read-side use cases in a
+// uniform way.
+//
+// Each query type is answered by exactly one handler. Cross-cutting concerns,
+// such as caching, are applied to every query by chaining middlewares around
+// handlers.
+package queries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/tangelo-labs/go-domain/internal/bus"
+)
+
+// Bus-related errors.
+var (
+	// ErrHandlerNotFound is returned when asking a query whose type has no
+	// registered handler.
+	ErrHandlerNotFound = errors.New("query handler not found")
+
+	// ErrHandlerAlreadyRegistered is returned when registering a handler for a
+	// query type that already has one.
+	ErrHandlerAlreadyRegistered = errors.New("query handler already registered")
+
+	// ErrInvalidQueryType is returned when registering a handler for a query
+	// type that cannot be routed, such as interfaces.
+	ErrInvalidQueryType = errors.New("invalid query type")
+
+	// ErrUnexpectedResult is returned when a handler result cannot be converted
+	// into the type expected by the caller.
+	ErrUnexpectedResult = errors.New("unexpected query result")
+)
+
+// Query represents a request for information that does not change the state
+// of the system, such as "GetOrder" or "ListPendingOrders".
+type Query interface{}
+
+// HandlerFunc is the untyped form of a query handler, as seen by middlewares.
+type HandlerFunc func(ctx context.Context, query Query) (interface{}, error)
+
+// Middleware wraps a query handler to add behavior before and/or after its
+// invocation.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Bus routes queries to their handlers.
+type Bus interface {
+	// Dispatch sends the given query to its handler and returns its result.
+	// Queries are routed by their dynamic type.
+	Dispatch(ctx context.Context, query Query) (interface{}, error)
+
+	// Use appends the given middlewares to the chain applied to every handler.
+	// Middlewares are invoked in the order they were added, the first one being
+	// the outermost.
+	Use(mws ...Middleware)
+
+	// RegisterHandler registers the untyped handler for queries of the given
+	// type. Most callers should prefer the typed Register function, which
+	// relies on this method.
+	RegisterHandler(queryType reflect.Type, handler HandlerFunc) error
+}
+
+// NewBus builds a new query bus with the given middlewares. This object can be
+// safely shared by multiple goroutines.
+func NewBus(mws ...Middleware) Bus {
+	return bus.New[Query, HandlerFunc]("query", bus.Errors{
+		NotFound:          ErrHandlerNotFound,
+		AlreadyRegistered: ErrHandlerAlreadyRegistered,
+		InvalidType:       ErrInvalidQueryType,
+	}, mws...)
+}
+
+// Register registers the handler for queries of type Q, producing results of
+// type R.
+func Register[Q, R any](b Bus, handler func(ctx context.Context, query Q) (R, error)) error {
+	queryType := reflect.TypeOf((*Q)(nil)).Elem()
+
+	return b.RegisterHandler(queryType, func(ctx context.Context, query Query) (interface{}, error) {
+		typed, ok := query.(Q)
+		if !ok {
+			return nil, fmt.Errorf("%w: handler for %s received %T", ErrInvalidQueryType, queryType, query)
+		}
+
+		return handler(ctx, typed)
+	})
+}
+
+// Ask dispatches the given query through the given bus and converts the
+// handler result into the type R.
+func Ask[R, Q any](ctx context.Context, b Bus, query Q) (R, error) {
+	var zero R
+
+	result, err := b.Dispatch(ctx, query)
+	if err != nil {
+		return zero, err
+	}
+
+	if result == nil {
+		return zero, nil
+	}
+
+	typed, ok := result.(R)
+	if !ok {
+		return zero, fmt.Errorf("%w: expected %s for query %T, got %T",
+			ErrUnexpectedResult, reflect.TypeOf((*R)(nil)).Elem(), query, result)
+	}
+
+	return typed, nil
+}
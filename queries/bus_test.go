@@ -0,0 +1,100 @@
+package queries_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain"
+	"github.com/tangelo-labs/go-domain/queries"
+)
+
+func TestBus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("GIVEN a bus with one handler for getOrder queries", func(t *testing.T) {
+		bus := queries.NewBus()
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q getOrder) (orderView, error) {
+			return orderView{ID: q.ID}, nil
+		}))
+
+		t.Run("WHEN asking a getOrder query THEN typed result is returned", func(t *testing.T) {
+			id := domain.NewID()
+
+			view, err := queries.Ask[orderView](ctx, bus, getOrder{ID: id})
+			require.NoError(t, err)
+			require.Equal(t, id, view.ID)
+		})
+
+		t.Run("WHEN registering a second handler for getOrder queries THEN registration fails", func(t *testing.T) {
+			err := queries.Register(bus, func(ctx context.Context, q getOrder) (string, error) {
+				return "", nil
+			})
+
+			require.ErrorIs(t, err, queries.ErrHandlerAlreadyRegistered)
+		})
+
+		t.Run("WHEN asking a query with no handler THEN dispatch fails", func(t *testing.T) {
+			_, err := bus.Dispatch(ctx, listOrders{})
+			require.ErrorIs(t, err, queries.ErrHandlerNotFound)
+		})
+
+		t.Run("WHEN asking expecting the wrong result type THEN an unexpected result error is returned", func(t *testing.T) {
+			_, err := queries.Ask[string](ctx, bus, getOrder{ID: domain.NewID()})
+			require.ErrorIs(t, err, queries.ErrUnexpectedResult)
+		})
+	})
+
+	t.Run("GIVEN a bus WHEN registering a handler for an interface query type THEN registration fails", func(t *testing.T) {
+		err := queries.Register(queries.NewBus(), func(ctx context.Context, q fmt.Stringer) (string, error) {
+			return "", nil
+		})
+
+		require.ErrorIs(t, err, queries.ErrInvalidQueryType)
+	})
+
+	t.Run("GIVEN a bus implemented outside the queries package", func(t *testing.T) {
+		bus := &registryBus{Bus: queries.NewBus()}
+
+		t.Run("WHEN registering a handler THEN it goes through the custom bus AND queries are answered", func(t *testing.T) {
+			require.NoError(t, queries.Register(bus, func(ctx context.Context, q getOrder) (orderView, error) {
+				return orderView{ID: q.ID}, nil
+			}))
+
+			id := domain.NewID()
+
+			view, err := queries.Ask[orderView](ctx, bus, getOrder{ID: id})
+			require.NoError(t, err)
+			require.Equal(t, id, view.ID)
+			require.Equal(t, []string{"queries_test.getOrder"}, bus.registered)
+		})
+	})
+}
+
+type registryBus struct {
+	queries.Bus
+	registered []string
+}
+
+func (r *registryBus) RegisterHandler(queryType reflect.Type, handler queries.HandlerFunc) error {
+	r.registered = append(r.registered, queryType.String())
+
+	return r.Bus.RegisterHandler(queryType, handler)
+}
+
+type getOrder struct {
+	ID domain.ID
+}
+
+type listOrders struct {
+	IDs []domain.ID
+}
+
+type orderView struct {
+	ID domain.ID
+}
@@ -0,0 +1,321 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+// Cache holds query results for a limited amount of time. Results are keyed by
+// the query value itself, so two equal queries share the same cached result.
+// Queries whose type is not comparable, e.g. structs holding slices, are never
+// cached. Neither are queries whose type holds interface fields, as these may
+// hold values that cannot be used as map keys at runtime, nor queries whose
+// type holds pointers or channels, as these would be keyed by address rather
+// than by value, serving stale results once the pointed values change.
+//
+// Cached results are shared by every caller, so they must be treated as
+// read-only unless a copy function is given, see WithCacheCopy.
+//
+// This object can be safely shared by multiple goroutines.
+type Cache struct {
+	ttl       time.Duration
+	clock     func() time.Time
+	copy      func(result interface{}) interface{}
+	entries   map[Query]cacheEntry
+	fills     map[Query]*cacheFill
+	nextSweep time.Time
+	mu        sync.Mutex
+}
+
+type cacheEntry struct {
+	result    interface{}
+	expiresAt time.Time
+}
+
+// cacheFill tracks the handlers being invoked on misses of a same query. Its
+// generation is bumped whenever the query is invalidated, so results computed
+// before that are not stored.
+type cacheFill struct {
+	gen     uint64
+	pending int
+}
+
+// CacheOption configures a Cache.
+type CacheOption func(*Cache)
+
+// WithCacheClock sets the function used by the cache to get the current time.
+// Defaults to time.Now.
+func WithCacheClock(clock func() time.Time) CacheOption {
+	return func(c *Cache) {
+		c.clock = clock
+	}
+}
+
+// WithCacheCopy sets the function used to copy results, so callers mutating
+// the results they get do not alter cached ones. Results are copied when
+// cached, and whenever served from the cache. Defaults to no copy.
+func WithCacheCopy(copy func(result interface{}) interface{}) CacheOption {
+	return func(c *Cache) {
+		c.copy = copy
+	}
+}
+
+// NewCache builds a new cache whose entries live for the given duration.
+func NewCache(ttl time.Duration, opts ...CacheOption) *Cache {
+	c := &Cache{
+		ttl:     ttl,
+		clock:   time.Now,
+		copy:    func(result interface{}) interface{} { return result },
+		entries: make(map[Query]cacheEntry),
+		fills:   make(map[Query]*cacheFill),
+	}
+
+	for i := range opts {
+		opts[i](c)
+	}
+
+	return c
+}
+
+// Middleware builds a middleware that serves results from this cache. Handlers
+// are only invoked on cache misses, and only successful results are cached.
+// Results of queries invalidated while their handler was running are not
+// cached, as they may already be stale.
+func (c *Cache) Middleware() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, query Query) (interface{}, error) {
+			if !c.cacheable(query) {
+				return next(ctx, query)
+			}
+
+			result, hit, gen := c.get(query)
+			if hit {
+				return c.copy(result), nil
+			}
+
+			resolved := false
+
+			// the query is released even if next panics, so it does not stay
+			// marked as being resolved forever.
+			defer func() {
+				c.set(query, result, gen, resolved)
+			}()
+
+			result, err := next(ctx, query)
+			if err != nil {
+				return nil, err
+			}
+
+			resolved = true
+
+			return result, nil
+		}
+	}
+}
+
+// Invalidate removes the cached result of the given query, if any.
+func (c *Cache) Invalidate(query Query) {
+	if !c.cacheable(query) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, query)
+
+	if fill, ok := c.fills[query]; ok {
+		fill.gen++
+	}
+}
+
+// InvalidateMatching removes the cached result of every query for which the
+// given predicate returns true. This operation is linear in the number of
+// cached entries. Queries being resolved are matched as well, so their results
+// are not cached once resolved.
+func (c *Cache) InvalidateMatching(match func(query Query) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for query := range c.entries {
+		if match(query) {
+			delete(c.entries, query)
+		}
+	}
+
+	for query, fill := range c.fills {
+		if match(query) {
+			fill.gen++
+		}
+	}
+}
+
+// Clear removes every cached result.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[Query]cacheEntry)
+
+	for _, fill := range c.fills {
+		fill.gen++
+	}
+}
+
+// Len returns the number of cached results, including expired ones not yet
+// evicted. Expired results are evicted when read, and swept at most once per
+// ttl when storing new results.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.entries)
+}
+
+// InvalidateOn subscribes to events of type E in the given dispatcher, and
+// removes from the given cache the result of every query for which the given
+// predicate returns true when such event is received.
+//
+// Example:
+//
+//	queries.InvalidateOn(ctx, dsp, cache, func(e order.LineAddedEvent, q queries.Query) bool {
+//		get, ok := q.(GetOrder)
+//
+//		return ok && get.ID == e.OrderID
+//	})
+func InvalidateOn[E any](
+	ctx context.Context,
+	d dispatcher.Dispatcher,
+	cache *Cache,
+	match func(event E, query Query) bool,
+) error {
+	_, err := d.Subscribe(ctx, func(ctx context.Context, event E) error {
+		cache.InvalidateMatching(func(query Query) bool {
+			return match(event, query)
+		})
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w: could not subscribe cache invalidation", err)
+	}
+
+	return nil
+}
+
+// get returns the cached result of the given query, if any. On misses, the
+// query is marked as being resolved, and the generation to give to set is
+// returned.
+func (c *Cache) get(query Query) (interface{}, bool, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[query]
+	if ok && c.clock().Before(entry.expiresAt) {
+		return entry.result, true, 0
+	}
+
+	if ok {
+		delete(c.entries, query)
+	}
+
+	fill, ok := c.fills[query]
+	if !ok {
+		fill = &cacheFill{}
+		c.fills[query] = fill
+	}
+
+	fill.pending++
+
+	return nil, false, fill.gen
+}
+
+// set marks the given query as resolved, and caches its result if told to,
+// unless the query was invalidated since the given generation.
+func (c *Cache) set(query Query, result interface{}, gen uint64, store bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fill := c.fills[query]
+	if fill.pending--; fill.pending == 0 {
+		delete(c.fills, query)
+	}
+
+	if !store || fill.gen != gen {
+		return
+	}
+
+	now := c.clock()
+	c.sweep(now)
+
+	c.entries[query] = cacheEntry{
+		result:    c.copy(result),
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// sweep evicts expired entries, at most once per ttl, so entries never read
+// again do not pile up. Must be called while holding the lock.
+func (c *Cache) sweep(now time.Time) {
+	if now.Before(c.nextSweep) {
+		return
+	}
+
+	for query, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, query)
+		}
+	}
+
+	c.nextSweep = now.Add(c.ttl)
+}
+
+// hashableTypes memoizes the result of hashable by type.
+var hashableTypes sync.Map
+
+func (c *Cache) cacheable(query Query) bool {
+	qt := reflect.TypeOf(query)
+	if qt == nil {
+		return false
+	}
+
+	if ok, seen := hashableTypes.Load(qt); seen {
+		return ok.(bool)
+	}
+
+	ok := hashable(qt)
+	hashableTypes.Store(qt, ok)
+
+	return ok
+}
+
+// hashable tells whether every value of the given type can be used as a map
+// key, and is compared by value. Comparable types holding interfaces are not,
+// as the dynamic value of such interfaces may not be comparable, making map
+// operations panic. Neither are types holding pointers or channels, which are
+// compared by address.
+func hashable(t reflect.Type) bool {
+	if !t.Comparable() {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.UnsafePointer, reflect.Chan:
+		return false
+	case reflect.Array:
+		return hashable(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !hashable(t.Field(i).Type) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
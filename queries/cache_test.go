@@ -0,0 +1,314 @@
+package queries_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+	"github.com/tangelo-labs/go-domain/queries"
+)
+
+func TestCache(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("GIVEN a bus with a caching middleware invalidated by order events", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+		cache := queries.NewCache(time.Minute, queries.WithCacheClock(clock.Now))
+		bus := queries.NewBus(cache.Middleware())
+		dsp := dispatcher.NewMemoryDispatcher()
+		calls := 0
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q getOrder) (orderView, error) {
+			calls++
+
+			return orderView{ID: q.ID}, nil
+		}))
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q listOrders) ([]orderView, error) {
+			calls++
+
+			return make([]orderView, len(q.IDs)), nil
+		}))
+
+		require.NoError(t, queries.InvalidateOn(ctx, dsp, cache, func(e orderUpdated, q queries.Query) bool {
+			get, ok := q.(getOrder)
+
+			return ok && get.ID == e.OrderID
+		}))
+
+		id := domain.NewID()
+
+		t.Run("WHEN asking the same query twice THEN handler is invoked once", func(t *testing.T) {
+			_, err := queries.Ask[orderView](ctx, bus, getOrder{ID: id})
+			require.NoError(t, err)
+
+			_, err = queries.Ask[orderView](ctx, bus, getOrder{ID: id})
+			require.NoError(t, err)
+
+			require.Equal(t, 1, calls)
+			require.Equal(t, 1, cache.Len())
+		})
+
+		t.Run("WHEN asking a different query THEN handler is invoked again", func(t *testing.T) {
+			_, err := queries.Ask[orderView](ctx, bus, getOrder{ID: domain.NewID()})
+			require.NoError(t, err)
+			require.Equal(t, 2, calls)
+		})
+
+		t.Run("WHEN the ttl expires THEN handler is invoked again", func(t *testing.T) {
+			clock.advance(2 * time.Minute)
+
+			_, err := queries.Ask[orderView](ctx, bus, getOrder{ID: id})
+			require.NoError(t, err)
+			require.Equal(t, 3, calls)
+		})
+
+		t.Run("WHEN a matching event is dispatched THEN the cached result is invalidated", func(t *testing.T) {
+			require.NoError(t, dsp.Dispatch(ctx, orderUpdated{OrderID: id}))
+
+			_, err := queries.Ask[orderView](ctx, bus, getOrder{ID: id})
+			require.NoError(t, err)
+			require.Equal(t, 4, calls)
+		})
+
+		t.Run("WHEN asking a non-comparable query twice THEN it is never cached", func(t *testing.T) {
+			q := listOrders{IDs: []domain.ID{id}}
+
+			_, err := queries.Ask[[]orderView](ctx, bus, q)
+			require.NoError(t, err)
+
+			_, err = queries.Ask[[]orderView](ctx, bus, q)
+			require.NoError(t, err)
+
+			require.Equal(t, 6, calls)
+		})
+	})
+
+	t.Run("GIVEN a bus with a caching middleware AND a slow handler", func(t *testing.T) {
+		cache := queries.NewCache(time.Minute)
+		bus := queries.NewBus(cache.Middleware())
+		started := make(chan struct{})
+		release := make(chan struct{})
+		calls := 0
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q getOrder) (orderView, error) {
+			calls++
+
+			if calls == 1 {
+				close(started)
+				<-release
+			}
+
+			return orderView{ID: q.ID}, nil
+		}))
+
+		t.Run("WHEN the query is invalidated while being resolved THEN its result is not cached", func(t *testing.T) {
+			q := getOrder{ID: domain.NewID()}
+			done := make(chan error)
+
+			go func() {
+				_, err := queries.Ask[orderView](ctx, bus, q)
+				done <- err
+			}()
+
+			<-started
+			cache.InvalidateMatching(func(query queries.Query) bool {
+				return query == q
+			})
+			close(release)
+
+			require.NoError(t, <-done)
+			require.Zero(t, cache.Len())
+
+			_, err := queries.Ask[orderView](ctx, bus, q)
+			require.NoError(t, err)
+			require.Equal(t, 2, calls)
+			require.Equal(t, 1, cache.Len())
+		})
+	})
+
+	t.Run("GIVEN a bus with a caching middleware AND many cached queries", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+		cache := queries.NewCache(time.Minute, queries.WithCacheClock(clock.Now))
+		bus := queries.NewBus(cache.Middleware())
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q getOrder) (orderView, error) {
+			return orderView{ID: q.ID}, nil
+		}))
+
+		for i := 0; i < 10; i++ {
+			_, err := queries.Ask[orderView](ctx, bus, getOrder{ID: domain.NewID()})
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, 10, cache.Len())
+
+		t.Run("WHEN they expire AND a new query is cached THEN expired entries are evicted", func(t *testing.T) {
+			clock.advance(2 * time.Minute)
+
+			_, err := queries.Ask[orderView](ctx, bus, getOrder{ID: domain.NewID()})
+			require.NoError(t, err)
+			require.Equal(t, 1, cache.Len())
+		})
+	})
+
+	t.Run("GIVEN a bus with a caching middleware AND a query type holding an interface field", func(t *testing.T) {
+		cache := queries.NewCache(time.Minute)
+		bus := queries.NewBus(cache.Middleware())
+		calls := 0
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q searchOrders) (int, error) {
+			calls++
+
+			return 0, nil
+		}))
+
+		t.Run("WHEN asking a query holding a slice twice THEN it does not panic AND it is never cached", func(t *testing.T) {
+			q := searchOrders{Filter: []string{"pending"}}
+
+			require.NotPanics(t, func() {
+				_, err := queries.Ask[int](ctx, bus, q)
+				require.NoError(t, err)
+
+				_, err = queries.Ask[int](ctx, bus, q)
+				require.NoError(t, err)
+			})
+
+			require.Equal(t, 2, calls)
+			require.Zero(t, cache.Len())
+		})
+	})
+
+	t.Run("GIVEN a bus with a caching middleware AND a failing handler", func(t *testing.T) {
+		cache := queries.NewCache(time.Minute)
+		bus := queries.NewBus(cache.Middleware())
+		errFailed := errors.New("failed")
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q getOrder) (orderView, error) {
+			return orderView{}, errFailed
+		}))
+
+		t.Run("WHEN asking a query THEN the error is returned AND nothing is cached", func(t *testing.T) {
+			_, err := queries.Ask[orderView](ctx, bus, getOrder{ID: domain.NewID()})
+			require.ErrorIs(t, err, errFailed)
+			require.Zero(t, cache.Len())
+		})
+	})
+
+	t.Run("GIVEN a bus with a caching middleware AND a query type holding a pointer", func(t *testing.T) {
+		cache := queries.NewCache(time.Minute)
+		bus := queries.NewBus(cache.Middleware())
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q *getOrder) (orderView, error) {
+			return orderView{ID: q.ID}, nil
+		}))
+
+		t.Run("WHEN the pointed query changes between asks THEN the fresh result is returned AND it is never cached", func(t *testing.T) {
+			q := &getOrder{ID: domain.NewID()}
+
+			_, err := queries.Ask[orderView](ctx, bus, q)
+			require.NoError(t, err)
+
+			q.ID = domain.NewID()
+
+			view, err := queries.Ask[orderView](ctx, bus, q)
+			require.NoError(t, err)
+			require.Equal(t, q.ID, view.ID)
+			require.Zero(t, cache.Len())
+		})
+	})
+
+	t.Run("GIVEN a bus with a caching middleware AND a handler that panics once", func(t *testing.T) {
+		cache := queries.NewCache(time.Minute)
+		bus := queries.NewBus(cache.Middleware())
+		calls := 0
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q getOrder) (orderView, error) {
+			calls++
+			if calls == 1 {
+				panic("boom")
+			}
+
+			return orderView{ID: q.ID}, nil
+		}))
+
+		t.Run("WHEN asking the same query after the panic THEN its result is cached", func(t *testing.T) {
+			q := getOrder{ID: domain.NewID()}
+
+			require.Panics(t, func() {
+				_, _ = queries.Ask[orderView](ctx, bus, q)
+			})
+
+			for i := 0; i < 2; i++ {
+				_, err := queries.Ask[orderView](ctx, bus, q)
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, 2, calls)
+			require.Equal(t, 1, cache.Len())
+		})
+	})
+
+	t.Run("GIVEN a bus with a caching middleware that copies results", func(t *testing.T) {
+		cache := queries.NewCache(time.Minute, queries.WithCacheCopy(func(result interface{}) interface{} {
+			return append([]orderView(nil), result.([]orderView)...)
+		}))
+		bus := queries.NewBus(cache.Middleware())
+
+		require.NoError(t, queries.Register(bus, func(ctx context.Context, q getOrder) ([]orderView, error) {
+			return []orderView{{ID: q.ID}}, nil
+		}))
+
+		t.Run("WHEN callers mutate the results they get THEN cached results are not altered", func(t *testing.T) {
+			id := domain.NewID()
+			q := getOrder{ID: id}
+
+			first, err := queries.Ask[[]orderView](ctx, bus, q)
+			require.NoError(t, err)
+
+			first[0].ID = domain.NewID()
+
+			second, err := queries.Ask[[]orderView](ctx, bus, q)
+			require.NoError(t, err)
+
+			second[0].ID = domain.NewID()
+
+			third, err := queries.Ask[[]orderView](ctx, bus, q)
+			require.NoError(t, err)
+			require.Equal(t, []orderView{{ID: id}}, third)
+		})
+	})
+}
+
+type searchOrders struct {
+	Filter interface{}
+}
+
+type orderUpdated struct {
+	OrderID domain.ID
+}
+
+type fakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,337 @@
+// Package saga provides a process manager for coordinating long-running
+// workflows that span multiple aggregates, such as "order created, reserve
+// stock, charge payment, confirm order".
+//
+// A saga reacts to domain events received through a dispatcher.Dispatcher.
+// Each event is correlated to a saga instance by a key, the instance state is
+// loaded from a Store, the matching step is executed, and the commands issued
+// by such step are delivered through a CommandSender once the new state has
+// been persisted. When a step fails, or an instance exceeds its deadline, the
+// compensating commands registered by previous steps are issued in reverse
+// order.
+//
+// Commands are persisted along with the instance before being sent, and those
+// that could not be sent are resent the next time the instance is processed,
+// so commands are delivered at least once. Note that events redelivered after
+// such failures run their step again, so steps should be idempotent.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tangelo-labs/go-domain"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+)
+
+// ErrTimedOut is the failure reason recorded for instances that exceeded their
+// deadline and were not handled by a timeout step.
+var ErrTimedOut = errors.New("saga timed out")
+
+// KeyFunc extracts the correlation key from an event of type E.
+type KeyFunc[E any] func(event E) string
+
+// StepFunc defines a saga step reacting to an event of type E.
+//
+// Returning an error aborts the step: nothing is persisted nor sent, and the
+// error is returned to the dispatcher. Business failures should be reported
+// using Step.Fail instead.
+type StepFunc[S, E any] func(ctx context.Context, step *Step[S], event E) error
+
+// TimeoutFunc defines a step that is executed when an instance exceeds its
+// deadline.
+type TimeoutFunc[S any] func(ctx context.Context, step *Step[S]) error
+
+// Option configures a Saga.
+type Option func(*options)
+
+type options struct {
+	clock   func() time.Time
+	timeout time.Duration
+}
+
+// WithClock sets the function used by the saga to get the current time.
+// Defaults to time.Now.
+func WithClock(clock func() time.Time) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithTimeout sets the default deadline for new instances, relative to the
+// moment they are started. Defaults to no deadline.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// Saga coordinates a workflow whose state is of type S.
+type Saga[S any] struct {
+	name        string
+	store       Store[S]
+	sender      CommandSender
+	opts        options
+	onTimeout   TimeoutFunc[S]
+	subscribers []func(ctx context.Context, d dispatcher.Dispatcher) error
+}
+
+// New builds a new saga identified by the given name. The name must be unique
+// within the given store.
+func New[S any](name string, store Store[S], sender CommandSender, opts ...Option) (*Saga[S], error) {
+	if name == "" {
+		return nil, fmt.Errorf("a saga name must be provided")
+	}
+
+	if store == nil {
+		return nil, fmt.Errorf("a valid saga store must be provided")
+	}
+
+	if sender == nil {
+		return nil, fmt.Errorf("a valid command sender must be provided")
+	}
+
+	o := options{
+		clock: time.Now,
+	}
+
+	for i := range opts {
+		opts[i](&o)
+	}
+
+	return &Saga[S]{
+		name:        name,
+		store:       store,
+		sender:      sender,
+		opts:        o,
+		subscribers: make([]func(ctx context.Context, d dispatcher.Dispatcher) error, 0),
+	}, nil
+}
+
+// Name returns the name of this saga.
+func (s *Saga[S]) Name() string {
+	return s.name
+}
+
+// StartOn registers a step that starts a new instance when an event of type E
+// is received. Events correlated to an instance that already exists are
+// ignored.
+func StartOn[S, E any](s *Saga[S], key KeyFunc[E], step StepFunc[S, E]) {
+	s.subscribers = append(s.subscribers, func(ctx context.Context, d dispatcher.Dispatcher) error {
+		_, err := d.Subscribe(ctx, func(ctx context.Context, event E) error {
+			return s.handle(ctx, key(event), true, func(ctx context.Context, st *Step[S]) error {
+				return step(ctx, st, event)
+			})
+		})
+
+		return err
+	})
+}
+
+// On registers a step that reacts to events of type E correlated to a running
+// instance. Events not correlated to any running instance are ignored.
+func On[S, E any](s *Saga[S], key KeyFunc[E], step StepFunc[S, E]) {
+	s.subscribers = append(s.subscribers, func(ctx context.Context, d dispatcher.Dispatcher) error {
+		_, err := d.Subscribe(ctx, func(ctx context.Context, event E) error {
+			return s.handle(ctx, key(event), false, func(ctx context.Context, st *Step[S]) error {
+				return step(ctx, st, event)
+			})
+		})
+
+		return err
+	})
+}
+
+// OnTimeout registers the step executed when an instance exceeds its deadline.
+// If no step is registered, timed out instances are failed with ErrTimedOut.
+func (s *Saga[S]) OnTimeout(step TimeoutFunc[S]) {
+	s.onTimeout = step
+}
+
+// Subscribe registers every step of this saga in the given dispatcher.
+func (s *Saga[S]) Subscribe(ctx context.Context, d dispatcher.Dispatcher) error {
+	for i := range s.subscribers {
+		if err := s.subscribers[i](ctx, d); err != nil {
+			return fmt.Errorf("%w: saga `%s` could not subscribe", err, s.name)
+		}
+	}
+
+	return nil
+}
+
+// CheckTimeouts processes every running instance whose deadline has passed.
+// This method is expected to be called periodically, see Run.
+func (s *Saga[S]) CheckTimeouts(ctx context.Context) error {
+	expired, err := s.store.FindExpired(ctx, s.name, s.opts.clock())
+	if err != nil {
+		return fmt.Errorf("%w: saga `%s` could not find expired instances", err, s.name)
+	}
+
+	var errs []error
+
+	for _, instance := range expired {
+		if pErr := s.process(ctx, instance, s.timeoutStep); pErr != nil {
+			errs = append(errs, pErr)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run calls CheckTimeouts every given interval until the given context is
+// cancelled. Errors are reported to the given function, if any.
+func (s *Saga[S]) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CheckTimeouts(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (s *Saga[S]) timeoutStep(ctx context.Context, st *Step[S]) error {
+	if s.onTimeout == nil {
+		st.Fail(ErrTimedOut)
+
+		return nil
+	}
+
+	if err := s.onTimeout(ctx, st); err != nil {
+		return err
+	}
+
+	// a timeout step that neither finishes the instance nor extends its
+	// deadline would be triggered forever.
+	if !st.completed && st.failure == nil && !st.instance.Deadline.After(st.now) {
+		st.Fail(ErrTimedOut)
+	}
+
+	return nil
+}
+
+func (s *Saga[S]) handle(ctx context.Context, key string, start bool, run func(context.Context, *Step[S]) error) error {
+	instance, err := s.store.Find(ctx, s.name, key)
+
+	switch {
+	case errors.Is(err, ErrInstanceNotFound):
+		if !start {
+			return nil
+		}
+
+		return s.process(ctx, s.newInstance(key), run)
+	case err != nil:
+		return fmt.Errorf("%w: saga `%s` could not load instance `%s`", err, s.name, key)
+	}
+
+	// commands that could not be sent last time are sent before anything else.
+	if len(instance.Pending) > 0 {
+		if err := s.flush(ctx, instance); err != nil {
+			return err
+		}
+	}
+
+	if start || instance.Status.IsFinal() {
+		return nil
+	}
+
+	return s.process(ctx, instance, run)
+}
+
+func (s *Saga[S]) process(ctx context.Context, instance *Instance[S], run func(context.Context, *Step[S]) error) error {
+	now := s.opts.clock()
+	st := newStep(instance, now)
+
+	if err := run(ctx, st); err != nil {
+		return fmt.Errorf("%w: saga `%s` step failed for instance `%s`", err, s.name, instance.Key)
+	}
+
+	commands := st.commands
+
+	switch {
+	case st.failure != nil:
+		commands = make([]Command, 0, len(instance.Compensations))
+		for i := len(instance.Compensations) - 1; i >= 0; i-- {
+			commands = append(commands, instance.Compensations[i])
+		}
+
+		instance.Status = StatusCompensated
+		instance.Reason = st.failure.Error()
+		instance.Deadline = time.Time{}
+	case st.completed:
+		instance.Status = StatusCompleted
+		instance.Deadline = time.Time{}
+	}
+
+	if st.failure == nil {
+		instance.Compensations = append(instance.Compensations, st.compensations...)
+	}
+
+	instance.Pending = append(instance.Pending, commands...)
+	instance.UpdatedAt = now
+
+	if err := s.store.Save(ctx, instance); err != nil {
+		return fmt.Errorf("%w: saga `%s` could not save instance `%s`", err, s.name, instance.Key)
+	}
+
+	return s.flush(ctx, instance)
+}
+
+// flush sends the pending commands of the given instance in order, stopping at
+// the first failure, and saves the instance without the commands sent.
+func (s *Saga[S]) flush(ctx context.Context, instance *Instance[S]) error {
+	var (
+		sent    int
+		sendErr error
+	)
+
+	for ; sent < len(instance.Pending); sent++ {
+		cmd := instance.Pending[sent]
+
+		if err := s.sender.Send(ctx, cmd); err != nil {
+			sendErr = fmt.Errorf("%w: saga `%s` could not send command %T for instance `%s`", err, s.name, cmd, instance.Key)
+
+			break
+		}
+	}
+
+	if sent == 0 {
+		return sendErr
+	}
+
+	instance.Pending = append(make([]Command, 0, len(instance.Pending)-sent), instance.Pending[sent:]...)
+
+	if err := s.store.Save(ctx, instance); err != nil {
+		return errors.Join(sendErr, fmt.Errorf("%w: saga `%s` could not save instance `%s`", err, s.name, instance.Key))
+	}
+
+	return sendErr
+}
+
+func (s *Saga[S]) newInstance(key string) *Instance[S] {
+	now := s.opts.clock()
+	instance := &Instance[S]{
+		ID:            domain.NewID(),
+		Saga:          s.name,
+		Key:           key,
+		Status:        StatusRunning,
+		Compensations: make([]Command, 0),
+		Pending:       make([]Command, 0),
+		StartedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if s.opts.timeout > 0 {
+		instance.Deadline = now.Add(s.opts.timeout)
+	}
+
+	return instance
+}
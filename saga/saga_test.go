@@ -0,0 +1,429 @@
+package saga_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+	"github.com/tangelo-labs/go-domain/events/dispatcher"
+	"github.com/tangelo-labs/go-domain/saga"
+)
+
+func TestSaga(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Run("GIVEN an order fulfillment saga subscribed to a memory dispatcher", func(t *testing.T) {
+		f := newFulfillment(t)
+		orderID := gofakeit.UUID()
+
+		t.Run("WHEN an order is created THEN a new instance is started AND stock is reserved", func(t *testing.T) {
+			require.NoError(t, f.dsp.Dispatch(ctx, orderCreated{OrderID: orderID, Amount: 100}))
+
+			instance, err := f.store.Find(ctx, f.saga.Name(), orderID)
+			require.NoError(t, err)
+			require.Equal(t, saga.StatusRunning, instance.Status)
+			require.EqualValues(t, 100, instance.Data.Amount)
+			require.Equal(t, []saga.Command{reserveStock{OrderID: orderID}}, f.sender.sent())
+		})
+
+		t.Run("WHEN the same order created event is received again THEN it is ignored", func(t *testing.T) {
+			require.NoError(t, f.dsp.Dispatch(ctx, orderCreated{OrderID: orderID, Amount: 100}))
+			require.Len(t, f.sender.sent(), 1)
+		})
+
+		t.Run("WHEN stock is reserved THEN payment is charged", func(t *testing.T) {
+			require.NoError(t, f.dsp.Dispatch(ctx, stockReserved{OrderID: orderID}))
+			require.Equal(t, chargePayment{OrderID: orderID, Amount: 100}, f.sender.last())
+		})
+
+		t.Run("WHEN payment is charged THEN order is confirmed AND instance is completed", func(t *testing.T) {
+			require.NoError(t, f.dsp.Dispatch(ctx, paymentCharged{OrderID: orderID}))
+			require.Equal(t, confirmOrder{OrderID: orderID}, f.sender.last())
+
+			instance, err := f.store.Find(ctx, f.saga.Name(), orderID)
+			require.NoError(t, err)
+			require.Equal(t, saga.StatusCompleted, instance.Status)
+		})
+
+		t.Run("WHEN an event is received for a completed instance THEN it is ignored", func(t *testing.T) {
+			sent := len(f.sender.sent())
+
+			require.NoError(t, f.dsp.Dispatch(ctx, paymentFailed{OrderID: orderID}))
+			require.Len(t, f.sender.sent(), sent)
+		})
+
+		t.Run("WHEN an event is received for an unknown instance THEN it is ignored", func(t *testing.T) {
+			sent := len(f.sender.sent())
+
+			require.NoError(t, f.dsp.Dispatch(ctx, stockReserved{OrderID: gofakeit.UUID()}))
+			require.Len(t, f.sender.sent(), sent)
+		})
+	})
+
+	t.Run("GIVEN a running instance that has reserved stock and refunding registered", func(t *testing.T) {
+		f := newFulfillment(t)
+		orderID := gofakeit.UUID()
+
+		require.NoError(t, f.dsp.Dispatch(ctx, orderCreated{OrderID: orderID, Amount: 50}))
+		require.NoError(t, f.dsp.Dispatch(ctx, stockReserved{OrderID: orderID}))
+
+		t.Run("WHEN payment fails THEN compensations are issued in reverse order AND instance is compensated", func(t *testing.T) {
+			require.NoError(t, f.dsp.Dispatch(ctx, paymentFailed{OrderID: orderID}))
+
+			sent := f.sender.sent()
+			require.Equal(t, []saga.Command{
+				reserveStock{OrderID: orderID},
+				chargePayment{OrderID: orderID, Amount: 50},
+				refundPayment{OrderID: orderID},
+				releaseStock{OrderID: orderID},
+			}, sent)
+
+			instance, err := f.store.Find(ctx, f.saga.Name(), orderID)
+			require.NoError(t, err)
+			require.Equal(t, saga.StatusCompensated, instance.Status)
+			require.Equal(t, errPaymentDeclined.Error(), instance.Reason)
+		})
+	})
+
+	t.Run("GIVEN a saga whose second step registers a compensation and fails", func(t *testing.T) {
+		store := saga.NewMemoryStore[fulfillmentState]()
+		sender := &recordingSender{}
+		dsp := dispatcher.NewMemoryDispatcher()
+		orderID := gofakeit.UUID()
+
+		sg, err := saga.New[fulfillmentState]("failing", store, sender)
+		require.NoError(t, err)
+
+		saga.StartOn(sg, func(e orderCreated) string { return e.OrderID },
+			func(ctx context.Context, step *saga.Step[fulfillmentState], e orderCreated) error {
+				step.Send(reserveStock{OrderID: e.OrderID})
+				step.Compensate(releaseStock{OrderID: e.OrderID})
+
+				return nil
+			})
+
+		saga.On(sg, func(e stockReserved) string { return e.OrderID },
+			func(ctx context.Context, step *saga.Step[fulfillmentState], e stockReserved) error {
+				step.Compensate(refundPayment{OrderID: e.OrderID})
+				step.Fail(errPaymentDeclined)
+
+				return nil
+			})
+
+		require.NoError(t, sg.Subscribe(ctx, dsp))
+
+		t.Run("WHEN the second step fails THEN only compensations of previous steps are issued", func(t *testing.T) {
+			require.NoError(t, dsp.Dispatch(ctx, orderCreated{OrderID: orderID}))
+			require.NoError(t, dsp.Dispatch(ctx, stockReserved{OrderID: orderID}))
+
+			require.Equal(t, []saga.Command{
+				reserveStock{OrderID: orderID},
+				releaseStock{OrderID: orderID},
+			}, sender.sent())
+		})
+	})
+
+	t.Run("GIVEN a running instance AND a failing command sender", func(t *testing.T) {
+		f := newFulfillment(t)
+		orderID := gofakeit.UUID()
+		errUnavailable := errors.New("unavailable")
+
+		require.NoError(t, f.dsp.Dispatch(ctx, orderCreated{OrderID: orderID, Amount: 10}))
+		require.NoError(t, f.dsp.Dispatch(ctx, stockReserved{OrderID: orderID}))
+
+		t.Run("WHEN the instance completes THEN the command that could not be sent is kept pending", func(t *testing.T) {
+			f.sender.fail(errUnavailable)
+
+			require.NoError(t, f.dsp.Dispatch(ctx, paymentCharged{OrderID: orderID}))
+
+			instance, err := f.store.Find(ctx, f.saga.Name(), orderID)
+			require.NoError(t, err)
+			require.Equal(t, saga.StatusCompleted, instance.Status)
+			require.Equal(t, []saga.Command{confirmOrder{OrderID: orderID}}, instance.Pending)
+		})
+
+		t.Run("WHEN the event is redelivered THEN the pending command is sent", func(t *testing.T) {
+			f.sender.fail(nil)
+
+			require.NoError(t, f.dsp.Dispatch(ctx, paymentCharged{OrderID: orderID}))
+			require.Equal(t, confirmOrder{OrderID: orderID}, f.sender.last())
+
+			instance, err := f.store.Find(ctx, f.saga.Name(), orderID)
+			require.NoError(t, err)
+			require.Empty(t, instance.Pending)
+		})
+	})
+
+	t.Run("GIVEN a running instance with a deadline", func(t *testing.T) {
+		f := newFulfillment(t)
+		orderID := gofakeit.UUID()
+
+		require.NoError(t, f.dsp.Dispatch(ctx, orderCreated{OrderID: orderID, Amount: 10}))
+
+		t.Run("WHEN checking timeouts before the deadline THEN nothing happens", func(t *testing.T) {
+			f.clock.advance(time.Minute)
+			require.NoError(t, f.saga.CheckTimeouts(ctx))
+			require.Len(t, f.sender.sent(), 1)
+		})
+
+		t.Run("WHEN checking timeouts after the deadline THEN compensations are issued AND instance is compensated", func(t *testing.T) {
+			f.clock.advance(time.Hour)
+			require.NoError(t, f.saga.CheckTimeouts(ctx))
+			require.Equal(t, releaseStock{OrderID: orderID}, f.sender.last())
+
+			instance, err := f.store.Find(ctx, f.saga.Name(), orderID)
+			require.NoError(t, err)
+			require.Equal(t, saga.StatusCompensated, instance.Status)
+			require.Equal(t, saga.ErrTimedOut.Error(), instance.Reason)
+		})
+
+		t.Run("WHEN checking timeouts again THEN compensations are not issued twice", func(t *testing.T) {
+			sent := len(f.sender.sent())
+
+			f.clock.advance(time.Hour)
+			require.NoError(t, f.saga.CheckTimeouts(ctx))
+			require.Len(t, f.sender.sent(), sent)
+		})
+	})
+
+	t.Run("GIVEN a saga with a timeout step that extends the deadline once", func(t *testing.T) {
+		f := newFulfillment(t)
+		orderID := gofakeit.UUID()
+		extensions := 0
+
+		f.saga.OnTimeout(func(ctx context.Context, step *saga.Step[fulfillmentState]) error {
+			if extensions == 0 {
+				extensions++
+				step.Timeout(time.Hour)
+			}
+
+			return nil
+		})
+
+		require.NoError(t, f.dsp.Dispatch(ctx, orderCreated{OrderID: orderID, Amount: 10}))
+
+		t.Run("WHEN deadline is reached for the first time THEN instance keeps running", func(t *testing.T) {
+			f.clock.advance(31 * time.Minute)
+			require.NoError(t, f.saga.CheckTimeouts(ctx))
+
+			instance, err := f.store.Find(ctx, f.saga.Name(), orderID)
+			require.NoError(t, err)
+			require.Equal(t, saga.StatusRunning, instance.Status)
+		})
+
+		t.Run("WHEN extended deadline is reached THEN instance is compensated", func(t *testing.T) {
+			f.clock.advance(2 * time.Hour)
+			require.NoError(t, f.saga.CheckTimeouts(ctx))
+
+			instance, err := f.store.Find(ctx, f.saga.Name(), orderID)
+			require.NoError(t, err)
+			require.Equal(t, saga.StatusCompensated, instance.Status)
+		})
+	})
+
+	t.Run("GIVEN a memory store holding an instance", func(t *testing.T) {
+		store := saga.NewMemoryStore[fulfillmentState]()
+		instance := &saga.Instance[fulfillmentState]{Saga: "test", Key: gofakeit.UUID(), Status: saga.StatusRunning}
+
+		require.NoError(t, store.Save(ctx, instance))
+
+		t.Run("WHEN saving a stale copy of the instance THEN a concurrency error is returned", func(t *testing.T) {
+			a, err := store.Find(ctx, "test", instance.Key)
+			require.NoError(t, err)
+
+			b, err := store.Find(ctx, "test", instance.Key)
+			require.NoError(t, err)
+
+			require.NoError(t, store.Save(ctx, a))
+			require.ErrorIs(t, store.Save(ctx, b), saga.ErrConcurrentUpdate)
+		})
+
+		t.Run("WHEN saga names and keys only match once joined THEN instances are kept apart", func(t *testing.T) {
+			require.NoError(t, store.Save(ctx, &saga.Instance[fulfillmentState]{Saga: "a/b", Key: "c", Data: fulfillmentState{Amount: 1}}))
+			require.NoError(t, store.Save(ctx, &saga.Instance[fulfillmentState]{Saga: "a", Key: "b/c", Data: fulfillmentState{Amount: 2}}))
+
+			found, err := store.Find(ctx, "a", "b/c")
+			require.NoError(t, err)
+			require.EqualValues(t, 2, found.Data.Amount)
+
+			found, err = store.Find(ctx, "a/b", "c")
+			require.NoError(t, err)
+			require.EqualValues(t, 1, found.Data.Amount)
+		})
+	})
+}
+
+var errPaymentDeclined = errors.New("payment declined")
+
+type orderCreated struct {
+	OrderID string
+	Amount  float64
+}
+
+type stockReserved struct {
+	OrderID string
+}
+
+type paymentCharged struct {
+	OrderID string
+}
+
+type paymentFailed struct {
+	OrderID string
+}
+
+type reserveStock struct {
+	OrderID string
+}
+
+type releaseStock struct {
+	OrderID string
+}
+
+type chargePayment struct {
+	OrderID string
+	Amount  float64
+}
+
+type refundPayment struct {
+	OrderID string
+}
+
+type confirmOrder struct {
+	OrderID string
+}
+
+type fulfillmentState struct {
+	Amount float64
+}
+
+type fulfillment struct {
+	saga   *saga.Saga[fulfillmentState]
+	store  saga.Store[fulfillmentState]
+	dsp    dispatcher.Dispatcher
+	sender *recordingSender
+	clock  *fakeClock
+}
+
+func newFulfillment(t *testing.T) *fulfillment {
+	f := &fulfillment{
+		store:  saga.NewMemoryStore[fulfillmentState](),
+		dsp:    dispatcher.NewMemoryDispatcher(),
+		sender: &recordingSender{},
+		clock:  &fakeClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	sg, err := saga.New[fulfillmentState]("order-fulfillment", f.store, f.sender,
+		saga.WithClock(f.clock.Now),
+		saga.WithTimeout(30*time.Minute),
+	)
+	require.NoError(t, err)
+
+	saga.StartOn(sg, func(e orderCreated) string { return e.OrderID },
+		func(ctx context.Context, step *saga.Step[fulfillmentState], e orderCreated) error {
+			step.Data().Amount = e.Amount
+			step.Send(reserveStock{OrderID: e.OrderID})
+			step.Compensate(releaseStock{OrderID: e.OrderID})
+
+			return nil
+		})
+
+	saga.On(sg, func(e stockReserved) string { return e.OrderID },
+		func(ctx context.Context, step *saga.Step[fulfillmentState], e stockReserved) error {
+			step.Send(chargePayment{OrderID: e.OrderID, Amount: step.Data().Amount})
+			step.Compensate(refundPayment{OrderID: e.OrderID})
+
+			return nil
+		})
+
+	saga.On(sg, func(e paymentCharged) string { return e.OrderID },
+		func(ctx context.Context, step *saga.Step[fulfillmentState], e paymentCharged) error {
+			step.Send(confirmOrder{OrderID: e.OrderID})
+			step.Complete()
+
+			return nil
+		})
+
+	saga.On(sg, func(e paymentFailed) string { return e.OrderID },
+		func(ctx context.Context, step *saga.Step[fulfillmentState], e paymentFailed) error {
+			step.Fail(errPaymentDeclined)
+
+			return nil
+		})
+
+	require.NoError(t, sg.Subscribe(context.Background(), f.dsp))
+	f.saga = sg
+
+	return f
+}
+
+type recordingSender struct {
+	commands []saga.Command
+	err      error
+	mu       sync.Mutex
+}
+
+func (r *recordingSender) Send(_ context.Context, cmd saga.Command) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	r.commands = append(r.commands, cmd)
+
+	return nil
+}
+
+// fail makes every following Send call fail with the given error, until
+// called again with nil.
+func (r *recordingSender) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = err
+}
+
+func (r *recordingSender) sent() []saga.Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]saga.Command, len(r.commands))
+	copy(out, r.commands)
+
+	return out
+}
+
+func (r *recordingSender) last() saga.Command {
+	sent := r.sent()
+	if len(sent) == 0 {
+		return nil
+	}
+
+	return sent[len(sent)-1]
+}
+
+type fakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
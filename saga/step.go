@@ -0,0 +1,103 @@
+package saga
+
+import (
+	"context"
+	"time"
+)
+
+// Command represents an intention to change the state of the system, issued by
+// saga steps and delivered through a CommandSender.
+type Command interface{}
+
+// CommandSender delivers commands issued by saga steps.
+type CommandSender interface {
+	// Send delivers the given command to its handler.
+	Send(ctx context.Context, cmd Command) error
+}
+
+// SendFunc is an adapter to allow the use of ordinary functions as
+// CommandSender.
+type SendFunc func(ctx context.Context, cmd Command) error
+
+// Send calls f(ctx, cmd).
+func (f SendFunc) Send(ctx context.Context, cmd Command) error {
+	return f(ctx, cmd)
+}
+
+// Step gives saga steps access to the state of the instance being processed,
+// and allows them to issue commands and to drive the instance lifecycle.
+//
+// Commands issued by a step are only sent once the step returns without error
+// and the instance has been successfully persisted along with them, see
+// Instance.Pending.
+type Step[S any] struct {
+	instance      *Instance[S]
+	now           time.Time
+	commands      []Command
+	compensations []Command
+	completed     bool
+	failure       error
+}
+
+func newStep[S any](instance *Instance[S], now time.Time) *Step[S] {
+	return &Step[S]{
+		instance: instance,
+		now:      now,
+		commands: make([]Command, 0),
+	}
+}
+
+// Key returns the correlation key of the instance being processed.
+func (s *Step[S]) Key() string {
+	return s.instance.Key
+}
+
+// Data returns a pointer to the user-defined state of the instance being
+// processed. Modifications are persisted when the step succeeds.
+func (s *Step[S]) Data() *S {
+	return &s.instance.Data
+}
+
+// Now returns the moment at which this step is being processed, as reported
+// by the saga clock.
+func (s *Step[S]) Now() time.Time {
+	return s.now
+}
+
+// Send issues a command.
+func (s *Step[S]) Send(cmd Command) {
+	s.commands = append(s.commands, cmd)
+}
+
+// Compensate registers a command that undoes the effects of a command issued
+// by this step. Compensating commands are only issued if the saga later fails
+// or times out, and in reverse registration order. Compensations registered by
+// a failing step are discarded, as the commands of such step are never sent.
+func (s *Step[S]) Compensate(cmd Command) {
+	s.compensations = append(s.compensations, cmd)
+}
+
+// Timeout sets the deadline of the instance to the given duration from now.
+// A zero or negative duration removes the deadline.
+func (s *Step[S]) Timeout(d time.Duration) {
+	if d <= 0 {
+		s.instance.Deadline = time.Time{}
+
+		return
+	}
+
+	s.instance.Deadline = s.now.Add(d)
+}
+
+// Complete marks the instance as successfully finished. Commands issued by
+// this step are still sent.
+func (s *Step[S]) Complete() {
+	s.completed = true
+}
+
+// Fail marks the instance as failed for the given reason. Commands and
+// compensations issued by this step are discarded, and the compensations
+// registered by previous steps are issued instead.
+func (s *Step[S]) Fail(reason error) {
+	s.failure = reason
+}
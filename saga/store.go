@@ -0,0 +1,106 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tangelo-labs/go-domain"
+)
+
+// Store-related errors.
+var (
+	// ErrInstanceNotFound is returned by stores when no saga instance exists
+	// for the requested correlation key.
+	ErrInstanceNotFound = errors.New("saga instance not found")
+
+	// ErrConcurrentUpdate is returned by stores when an instance being saved
+	// was modified by someone else since it was loaded.
+	ErrConcurrentUpdate = errors.New("saga instance was concurrently updated")
+)
+
+// Status describes the lifecycle stage of a saga instance.
+type Status string
+
+// List of known saga statuses.
+const (
+	// StatusRunning the saga has started and is waiting for more events.
+	StatusRunning Status = "running"
+
+	// StatusCompleted the saga has finished successfully.
+	StatusCompleted Status = "completed"
+
+	// StatusCompensated the saga has failed or timed out, and its compensating
+	// commands have been issued.
+	StatusCompensated Status = "compensated"
+)
+
+// IsFinal whether this status denotes a saga that will not react to any more
+// events.
+func (s Status) IsFinal() bool {
+	return s == StatusCompleted || s == StatusCompensated
+}
+
+// Instance holds the persisted state of a single saga execution, correlated
+// by a key such as the ID of the aggregate that started the workflow.
+type Instance[S any] struct {
+	// ID uniquely identifies this instance.
+	ID domain.ID
+
+	// Saga is the name of the saga this instance belongs to.
+	Saga string
+
+	// Key is the correlation key used to route events to this instance.
+	Key string
+
+	// Status is the current lifecycle stage of this instance.
+	Status Status
+
+	// Data is the user-defined state of the saga. Stores are not required to
+	// deep-copy it, so it should be a value type: slices, maps or pointers
+	// held by it may be shared with the stored instance.
+	Data S
+
+	// Compensations is the list of compensating commands registered so far, in
+	// the order they were registered. They are issued in reverse order when
+	// the saga fails.
+	Compensations []Command
+
+	// Pending is the list of commands issued by the last processed steps that
+	// have not been sent yet, in the order they must be sent. They are saved
+	// before being sent, and those that could not be sent are resent the next
+	// time the instance is processed, so commands are sent at least once.
+	Pending []Command
+
+	// Reason holds the failure reason of a compensated instance.
+	Reason string
+
+	// Deadline is the moment after which this instance is considered timed
+	// out. Zero value means no deadline.
+	Deadline time.Time
+
+	// Version is used by stores for optimistic concurrency control.
+	Version int
+
+	// StartedAt is the moment this instance was created.
+	StartedAt time.Time
+
+	// UpdatedAt is the moment this instance was last modified.
+	UpdatedAt time.Time
+}
+
+// Store persists saga instances.
+type Store[S any] interface {
+	// Find retrieves the instance of the given saga correlated by the given
+	// key. If no such instance exists, ErrInstanceNotFound must be returned.
+	Find(ctx context.Context, saga, key string) (*Instance[S], error)
+
+	// Save persists the given instance. If the stored version of the instance
+	// does not match the version of the given one, ErrConcurrentUpdate must be
+	// returned. On success, the version of the given instance is incremented.
+	Save(ctx context.Context, instance *Instance[S]) error
+
+	// FindExpired retrieves every running instance of the given saga whose
+	// deadline is before or equal to the given moment.
+	FindExpired(ctx context.Context, saga string, now time.Time) ([]*Instance[S], error)
+}
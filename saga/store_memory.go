@@ -0,0 +1,101 @@
+package saga
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryStore[S any] struct {
+	instances map[instanceKey]*Instance[S]
+	mu        sync.RWMutex
+}
+
+// instanceKey identifies an instance within a memory store.
+type instanceKey struct {
+	saga string
+	key  string
+}
+
+// NewMemoryStore builds a store that keeps saga instances in local memory in a
+// thread-safe way. Suitable for testing or single-process applications.
+//
+// Instances are copied when found and saved, but their Data is copied as is:
+// S must be a value type, otherwise slices, maps or pointers held by it are
+// shared with the stored instance, and mutating them bypasses versioning.
+func NewMemoryStore[S any]() Store[S] {
+	return &memoryStore[S]{
+		instances: make(map[instanceKey]*Instance[S]),
+	}
+}
+
+func (m *memoryStore[S]) Find(_ context.Context, saga, key string) (*Instance[S], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	instance, ok := m.instances[instanceKey{saga: saga, key: key}]
+	if !ok {
+		return nil, fmt.Errorf("%w: saga `%s` with key `%s`", ErrInstanceNotFound, saga, key)
+	}
+
+	return m.copy(instance), nil
+}
+
+func (m *memoryStore[S]) Save(_ context.Context, instance *Instance[S]) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := instanceKey{saga: instance.Saga, key: instance.Key}
+
+	current, exists := m.instances[idx]
+
+	switch {
+	case exists && current.Version != instance.Version:
+		return fmt.Errorf("%w: saga `%s` with key `%s`, expected version %d got %d",
+			ErrConcurrentUpdate, instance.Saga, instance.Key, current.Version, instance.Version)
+	case !exists && instance.Version != 0:
+		return fmt.Errorf("%w: saga `%s` with key `%s`", ErrInstanceNotFound, instance.Saga, instance.Key)
+	}
+
+	instance.Version++
+	m.instances[idx] = m.copy(instance)
+
+	return nil
+}
+
+func (m *memoryStore[S]) FindExpired(_ context.Context, saga string, now time.Time) ([]*Instance[S], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]*Instance[S], 0)
+
+	for _, instance := range m.instances {
+		if instance.Saga != saga || instance.Status != StatusRunning || instance.Deadline.IsZero() {
+			continue
+		}
+
+		if instance.Deadline.After(now) {
+			continue
+		}
+
+		out = append(out, m.copy(instance))
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Deadline.Before(out[j].Deadline)
+	})
+
+	return out, nil
+}
+
+func (m *memoryStore[S]) copy(instance *Instance[S]) *Instance[S] {
+	out := *instance
+	out.Compensations = make([]Command, len(instance.Compensations))
+	copy(out.Compensations, instance.Compensations)
+	out.Pending = make([]Command, len(instance.Pending))
+	copy(out.Pending, instance.Pending)
+
+	return &out
+}